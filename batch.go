@@ -0,0 +1,269 @@
+package ta
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+type batchParams struct {
+	lookupOptions []LookupOption
+	workers       int
+}
+
+func (model *SpellModel) defaultBatchParams() *batchParams {
+	return &batchParams{
+		workers: runtime.GOMAXPROCS(0),
+	}
+}
+
+// BatchOption is a function that controls how a batch of lookups is performed.
+// An error will be returned if the BatchOption is invalid.
+type BatchOption func(*batchParams) error
+
+// BatchLookupOpts allows you to configure Lookup during a batch. Whether the
+// lookup of an input was truncated is reported by BatchResult.Truncated rather
+// than through the Truncated option, which is shared by every lookup.
+func BatchLookupOpts(opts ...LookupOption) BatchOption {
+	return func(bp *batchParams) error {
+		bp.lookupOptions = opts
+		return nil
+	}
+}
+
+// Workers sets how many lookups of a batch run concurrently. By default it is
+// the number of CPUs Go may use.
+func Workers(n int) BatchOption {
+	return func(bp *batchParams) error {
+		if n < 1 {
+			return errors.New("number of workers must be greater than 0")
+		}
+		bp.workers = n
+		return nil
+	}
+}
+
+// BatchResult is the result of the lookup of one input of a batch
+type BatchResult struct {
+	// The position of the input in the batch
+	Index       int
+	Input       string
+	Suggestions utils.SuggestionList
+	Err         error
+	// Whether the lookup ran out of its time budget, see TimeBudget
+	Truncated bool
+}
+
+func (model *SpellModel) newBatchParams(opts []BatchOption) (*batchParams, error) {
+	batchParams := model.defaultBatchParams()
+
+	for _, opt := range opts {
+		if err := opt(batchParams); err != nil {
+			return nil, err
+		}
+	}
+
+	// Report invalid lookup options once rather than for every input
+	if _, err := model.newLookupParams(batchParams.lookupOptions); err != nil {
+		return nil, err
+	}
+
+	return batchParams, nil
+}
+
+// batchLookup returns a lookup function for a single worker. Unless another
+// distance function is set, it reuses the memory of its distance buffers
+// between lookups. The extra options are applied after those of the batch.
+func (model *SpellModel) batchLookup(batchParams *batchParams, extra ...LookupOption) func(int, string) BatchResult {
+	var x, y []int
+
+	distance := func(r1, r2 []rune, maxDist int) int {
+		if size := utils.Max(len(r1), len(r2)); len(x) < size {
+			x = make([]int, size)
+			y = make([]int, size)
+		}
+		return utils.DamerauLevenshteinRunesBuffer(r1, r2, maxDist, x, y)
+	}
+
+	// The options of the batch come last so they can override the distance,
+	// except for Truncated, which each worker keeps to itself
+	var truncated bool
+	opts := append([]LookupOption{DistanceFunc(distance)}, batchParams.lookupOptions...)
+	opts = append(opts, Truncated(&truncated))
+	opts = append(opts, extra...)
+
+	return func(index int, input string) BatchResult {
+		suggestions, err := model.Lookup(input, opts...)
+		return BatchResult{
+			Index:       index,
+			Input:       input,
+			Suggestions: suggestions,
+			Err:         err,
+			Truncated:   truncated,
+		}
+	}
+}
+
+// LookupBatch looks up every input concurrently and returns the results in the
+// order of the inputs. The lookup of each input may fail on its own, see
+// BatchResult.Err.
+//
+// Accepts zero or more BatchOption that can be used to configure how the
+// batch is performed.
+func (model *SpellModel) LookupBatch(inputs []string, opts ...BatchOption) ([]BatchResult, error) {
+	batchParams, err := model.newBatchParams(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(inputs))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < utils.Min(batchParams.workers, len(inputs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lookup := model.batchLookup(batchParams)
+			for i := range jobs {
+				results[i] = lookup(i, inputs[i])
+			}
+		}()
+	}
+
+	for i := range inputs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, nil
+}
+
+// LookupStream looks up the inputs received on a channel concurrently. Results
+// are sent in the order of the inputs, and the results channel is closed once
+// the inputs channel is closed and every input has been looked up. The results
+// must be received until then, see LookupStreamContext to stop early.
+//
+// Accepts zero or more BatchOption that can be used to configure how the
+// batch is performed.
+func (model *SpellModel) LookupStream(inputs <-chan string, opts ...BatchOption) (<-chan BatchResult, error) {
+	return model.LookupStreamContext(context.Background(), inputs, opts...)
+}
+
+// LookupStreamContext is the same as LookupStream but stops once ctx is done,
+// closing the results channel without sending the remaining results, so that
+// its workers don't wait for results that won't be received. The inputs
+// channel doesn't need to be closed then.
+func (model *SpellModel) LookupStreamContext(ctx context.Context, inputs <-chan string, opts ...BatchOption) (<-chan BatchResult, error) {
+	batchParams, err := model.newBatchParams(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	type job struct {
+		index int
+		input string
+	}
+
+	jobs := make(chan job)
+	done := make(chan BatchResult)
+	results := make(chan BatchResult)
+
+	// Limit how many inputs are in flight, so that results waiting for an
+	// earlier one to be sent don't pile up
+	inFlight := make(chan struct{}, 2*batchParams.workers)
+
+	go func() {
+		defer close(jobs)
+
+		for index := 0; ; index++ {
+			var input string
+			select {
+			case in, ok := <-inputs:
+				if !ok {
+					return
+				}
+				input = in
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case inFlight <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case jobs <- job{index: index, input: input}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < batchParams.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lookup := model.batchLookup(batchParams, withContext(ctx))
+			for {
+				var j job
+				select {
+				case next, ok := <-jobs:
+					if !ok {
+						return
+					}
+					j = next
+				case <-ctx.Done():
+					return
+				}
+
+				select {
+				case done <- lookup(j.index, j.input):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	go func() {
+		defer close(results)
+
+		pending := make(map[int]BatchResult)
+		next := 0
+
+		for result := range done {
+			pending[result.Index] = result
+
+			for {
+				result, exists := pending[next]
+				if !exists {
+					break
+				}
+				delete(pending, next)
+
+				select {
+				case results <- result:
+				case <-ctx.Done():
+					return
+				}
+				<-inFlight
+				next++
+			}
+		}
+	}()
+
+	return results, nil
+}
@@ -0,0 +1,218 @@
+package ta
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+func newWithBatch() *SpellModel {
+	s := NewSpellModel()
+	for i, word := range []string{"the", "quick", "brown", "fox", "jumps", "over", "lazy", "dog"} {
+		_, _ = s.AddEntry(utils.Entry{Frequency: uint64(10 + i), Word: word})
+	}
+	return s
+}
+
+var batchInputs = []string{"teh", "qiuck", "brwn", "fox", "jumsp", "ovre", "lazzy", "dgo", "xxxxxxx"}
+
+func BenchmarkSpell_LookupBatch(b *testing.B) {
+	s := newWithBatch()
+
+	for n := 0; n < b.N; n++ {
+		if _, err := s.LookupBatch(batchInputs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func ExampleSpellModel_LookupBatch() {
+	s := newWithBatch()
+
+	results, _ := s.LookupBatch([]string{"teh", "qiuck", "brwn"}, Workers(2))
+	for _, result := range results {
+		fmt.Println(result.Index, result.Input, result.Suggestions)
+	}
+	// Output:
+	// 0 teh [the]
+	// 1 qiuck [quick]
+	// 2 brwn [brown]
+}
+
+func TestLookupBatch(t *testing.T) {
+	s := newWithBatch()
+
+	for _, workers := range []int{1, 3, 16} {
+		results, err := s.LookupBatch(batchInputs, Workers(workers),
+			BatchLookupOpts(SuggestionLevel(ALL)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != len(batchInputs) {
+			t.Fatalf("Expected %d results, got %d", len(batchInputs), len(results))
+		}
+
+		for i, result := range results {
+			want, _ := s.Lookup(batchInputs[i], SuggestionLevel(ALL))
+			if result.Index != i || result.Input != batchInputs[i] || result.Err != nil ||
+				!reflect.DeepEqual(result.Suggestions, want) {
+				t.Fatalf("Workers(%d): unexpected result %d: %+v, want %v", workers, i, result, want)
+			}
+		}
+	}
+
+	if _, err := s.LookupBatch(batchInputs, Workers(0)); err == nil {
+		t.Fatal("Expected an error for no workers")
+	}
+	if _, err := s.LookupBatch(batchInputs, BatchLookupOpts(PrefixLength(0))); err == nil {
+		t.Fatal("Expected an error for an invalid lookup option")
+	}
+
+	// Errors are reported for each input
+	results, err := s.LookupBatch([]string{"teh"}, BatchLookupOpts(NoisyChannel(true)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Err == nil {
+		t.Fatal("Expected an error for the input")
+	}
+
+	// Truncation is reported for each input
+	results, err = s.LookupBatch([]string{"teh", "teh"}, Workers(2),
+		BatchLookupOpts(TimeBudget(time.Nanosecond), SuggestionLevel(ALL)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !results[0].Truncated || !results[1].Truncated {
+		t.Fatalf("Expected truncated results, got %+v", results)
+	}
+
+	results, err = s.LookupBatch(nil)
+	if err != nil || len(results) != 0 {
+		t.Fatalf("Expected no results, got %v, %v", results, err)
+	}
+}
+
+func TestLookupStream(t *testing.T) {
+	s := newWithBatch()
+
+	inputs := make(chan string)
+	go func() {
+		for i := 0; i < 20; i++ {
+			for _, input := range batchInputs {
+				inputs <- input
+			}
+		}
+		close(inputs)
+	}()
+
+	results, err := s.LookupStream(inputs, Workers(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	i := 0
+	for result := range results {
+		input := batchInputs[i%len(batchInputs)]
+		want, _ := s.Lookup(input)
+		if result.Index != i || result.Input != input || !reflect.DeepEqual(result.Suggestions, want) {
+			t.Fatalf("Unexpected result %d: %+v, want %v", i, result, want)
+		}
+		i++
+	}
+
+	if i != 20*len(batchInputs) {
+		t.Fatalf("Expected %d results, got %d", 20*len(batchInputs), i)
+	}
+}
+
+func TestLookupStreamContext(t *testing.T) {
+	s := newWithBatch()
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Inputs keep coming until the consumer gives up
+	inputs := make(chan string)
+	go func() {
+		defer close(inputs)
+		for i := 0; ; i++ {
+			select {
+			case inputs <- batchInputs[i%len(batchInputs)]:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	results, err := s.LookupStreamContext(ctx, inputs, Workers(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if result := <-results; result.Index != i {
+			t.Fatalf("Unexpected result %d: %+v", i, result)
+		}
+	}
+	cancel()
+
+	// The results channel is closed without every result being received
+	for range results {
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Fatalf("Expected the workers to stop, %d goroutines left of %d", n, before)
+	}
+}
+
+func TestLookupStreamContext_openInputs(t *testing.T) {
+	s := newWithBatch()
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// The inputs are never closed
+	inputs := make(chan string, 1)
+	inputs <- batchInputs[0]
+
+	results, err := s.LookupStreamContext(ctx, inputs, Workers(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result := <-results; result.Index != 0 {
+		t.Fatalf("Unexpected result %+v", result)
+	}
+	cancel()
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for range results {
+		}
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the results channel to be closed")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Fatalf("Expected the workers to stop, %d goroutines left of %d", n, before)
+	}
+}
@@ -0,0 +1,152 @@
+package ta
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+const (
+	// bigramBackoff is applied to the unigram probability of a word when the
+	// bigram it is part of has never been seen
+	bigramBackoff = 0.4
+	// contextEditPenalty is the log10 probability penalty applied for every
+	// edit when candidates are ranked by context without an error model
+	contextEditPenalty = 1.0
+)
+
+// AddBigram adds the count of the word pair w1 w2 to the dictionary. If the
+// pair already exists its count will be increased, unless OverrideFrequency is
+// set. Returns true if a new pair was added, false otherwise.
+func (model *SpellModel) AddBigram(w1, w2 string, freq uint64, opts ...utils.DictionaryOption) (bool, error) {
+	dictOpts := model.defaultDictOptions()
+
+	for _, opt := range opts {
+		if err := opt(dictOpts); err != nil {
+			return false, err
+		}
+	}
+
+	w1, w2 = model.normalize(w1), model.normalize(w2)
+
+	count, exists := model.bigrams.Load(dictOpts.Name, w1, w2)
+	if exists && !dictOpts.OverrideFrequency {
+		freq += count
+	}
+
+	model.bigrams.Store(dictOpts.Name, w1, w2, freq)
+
+	return !exists, nil
+}
+
+// GetBigram returns the count of the word pair w1 w2. If the pair does not
+// exist, 0 will be returned
+func (model *SpellModel) GetBigram(w1, w2 string, opts ...utils.DictionaryOption) (uint64, error) {
+	dictOpts := model.defaultDictOptions()
+
+	for _, opt := range opts {
+		if err := opt(dictOpts); err != nil {
+			return 0, err
+		}
+	}
+
+	count, _ := model.bigrams.Load(dictOpts.Name, model.normalize(w1), model.normalize(w2))
+	return count, nil
+}
+
+// CreateBigramDictionary loads bigram counts from a file where each line holds
+// two words and a count separated by whitespace, e.g. "to buy 1500". Blank
+// lines are ignored. Merges with any bigram data already loaded.
+func (model *SpellModel) CreateBigramDictionary(filePath string, opts ...utils.DictionaryOption) (bool, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	line := 0
+
+	for s.Scan() {
+		line++
+
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return false, fmt.Errorf("%s:%d: expected two words and a count", filePath, line)
+		}
+
+		count, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil {
+			return false, fmt.Errorf("%s:%d: invalid count: %v", filePath, line, err)
+		}
+
+		if _, err := model.AddBigram(fields[0], fields[1], count, opts...); err != nil {
+			return false, err
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+type lookupContext struct {
+	previous string
+	next     string
+}
+
+// ContextWords sets the words around the input so that candidates can be
+// reranked with the bigrams of the dictionary. Either word may be empty.
+//
+// With context every candidate within the edit distance is considered, even if
+// the input is in the dictionary, and ranked by the probability of appearing
+// between the context words. BEST returns the most likely candidate and
+// CLOSEST the candidates with the same distance as the most likely one.
+func ContextWords(previous, next string) LookupOption {
+	return func(lp *lookupParams) error {
+		lp.context = &lookupContext{
+			previous: previous,
+			next:     next,
+		}
+		return nil
+	}
+}
+
+// bigramProbability returns the probability of w2 following w1 if the bigram
+// w1 w2 is in the dictionary
+func (model *SpellModel) bigramProbability(dict, w1, w2 string) (float64, bool) {
+	w1, w2 = model.normalize(w1), model.normalize(w2)
+
+	count, exists := model.bigrams.Load(dict, w1, w2)
+	if !exists || count == 0 {
+		return 0, false
+	}
+
+	entry, _ := model.library.Load(dict, w1)
+	return float64(count) / float64(utils.Max(int(entry.Frequency), int(count))), true
+}
+
+// conditionalProbability returns the probability of w2 following w1, backing
+// off to the unigram probability of w2 if the bigram is unknown
+func (model *SpellModel) conditionalProbability(dict, w1, w2 string) float64 {
+	if p, exists := model.bigramProbability(dict, w1, w2); exists {
+		return p
+	}
+
+	cumulativeFreq := float64(atomic.LoadUint64(&model.cumulativeFreq))
+	entry, _ := model.library.Load(dict, model.normalize(w2))
+
+	return bigramBackoff * float64(utils.Max(int(entry.Frequency), 1)) /
+		math.Max(cumulativeFreq, 1)
+}
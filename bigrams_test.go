@@ -0,0 +1,170 @@
+package ta
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+func newWithBigrams() *SpellModel {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Frequency: 1000, Word: "to"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 1000, Word: "a"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 500, Word: "by"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 100, Word: "buy"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 100, Word: "car"})
+	_, _ = s.AddBigram("to", "buy", 50)
+	_, _ = s.AddBigram("buy", "a", 30)
+	_, _ = s.AddBigram("by", "a", 2)
+	return s
+}
+
+func ExampleContextWords() {
+	s := newWithBigrams()
+
+	// Without context the exact match is returned
+	suggestions, _ := s.Lookup("by")
+	fmt.Println(suggestions)
+
+	// With context "to ... a" the most likely word is returned
+	suggestions, _ = s.Lookup("by", ContextWords("to", "a"))
+	fmt.Println(suggestions)
+	// Output:
+	// [by]
+	// [buy]
+}
+
+func TestAddBigram(t *testing.T) {
+	s := NewSpellModel()
+
+	ok, err := s.AddBigram("to", "buy", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("failed to add bigram")
+	}
+
+	if ok, _ := s.AddBigram("to", "buy", 5); ok {
+		t.Fatal("bigram should already exist")
+	}
+	if count, _ := s.GetBigram("to", "buy"); count != 15 {
+		t.Fatalf("Expected count 15, got %d", count)
+	}
+
+	_, _ = s.AddBigram("to", "buy", 3, OverrideFrequency(true))
+	if count, _ := s.GetBigram("to", "buy"); count != 3 {
+		t.Fatalf("Expected count 3, got %d", count)
+	}
+
+	if count, _ := s.GetBigram("to", "buy", DictionaryName("other")); count != 0 {
+		t.Fatal("Should get no count for bigram in different dictionary")
+	}
+}
+
+func TestCreateBigramDictionary(t *testing.T) {
+	f, err := ioutil.TempFile("", "bigrams")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	_, _ = f.WriteString("to buy 50\n\nbuy a 30\n")
+	_ = f.Close()
+
+	s := NewSpellModel()
+	if _, err := s.CreateBigramDictionary(f.Name(), DictionaryName("english")); err != nil {
+		t.Fatal(err)
+	}
+	if count, _ := s.GetBigram("buy", "a", DictionaryName("english")); count != 30 {
+		t.Fatalf("Expected count 30, got %d", count)
+	}
+
+	_ = ioutil.WriteFile(f.Name(), []byte("to buy\n"), 0644)
+	if _, err := s.CreateBigramDictionary(f.Name()); err == nil {
+		t.Fatal("Expected an error for a malformed line")
+	}
+}
+
+func TestLookup_contextWords(t *testing.T) {
+	s := newWithBigrams()
+
+	suggestions, err := s.Lookup("by", ContextWords("to", "a"), SuggestionLevel(CLOSEST))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[buy]" {
+		t.Fatalf("Expected [buy], got %v", suggestions)
+	}
+
+	suggestions, err = s.Lookup("by", ContextWords("to", "a"), SuggestionLevel(ALL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) < 2 || suggestions[0].Word != "buy" {
+		t.Fatalf("Expected buy to be ranked first, got %v", suggestions)
+	}
+
+	// Context without bigrams does not change a correct word
+	suggestions, err = s.Lookup("car", ContextWords("a", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[car]" {
+		t.Fatalf("Expected [car], got %v", suggestions)
+	}
+}
+
+func TestCheckText_bigrams(t *testing.T) {
+	s := newWithBigrams()
+
+	issues, err := s.CheckText("to by a car")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].Token != "by" {
+		t.Fatalf("Expected a single issue for by, got %v", issues)
+	}
+	if issues[0].Suggestions[0].Word != "buy" {
+		t.Fatalf("Expected buy, got %v", issues[0].Suggestions)
+	}
+
+	// Words across punctuation or numbers are not context
+	for _, text := range []string{"to. by! a car", "to, by; a car", "to 2 by 2 a car"} {
+		issues, err := s.CheckText(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(issues) != 0 {
+			t.Errorf("Expected no issues for %q, got %v", text, issues)
+		}
+	}
+}
+
+func TestSaveLoad_bigrams(t *testing.T) {
+	s1 := newWithBigrams()
+	_, _ = s1.AddEntry(utils.Entry{Frequency: 1, Word: "ciao"}, DictionaryName("italian"))
+	_, _ = s1.AddBigram("ciao", "ciao", 7, DictionaryName("italian"))
+
+	defer os.Remove("./test_bigrams.dump")
+	if err := s1.Save("./test_bigrams.dump"); err != nil {
+		t.Fatal(err)
+	}
+	s2, err := Load("./test_bigrams.dump")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count, _ := s2.GetBigram("to", "buy"); count != 50 {
+		t.Fatalf("Expected count 50, got %d", count)
+	}
+	if count, _ := s2.GetBigram("ciao", "ciao", DictionaryName("italian")); count != 7 {
+		t.Fatalf("Expected count 7, got %d", count)
+	}
+	if entry, _ := s2.GetEntry("ciao", DictionaryName("italian")); entry == nil {
+		t.Fatal("Expected entry to be loaded into its dictionary")
+	}
+}
@@ -0,0 +1,141 @@
+package ta
+
+import (
+	"unicode"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+type checkParams struct {
+	lookupOptions []LookupOption
+}
+
+func (model *SpellModel) defaultCheckParams() *checkParams {
+	return &checkParams{
+		lookupOptions: []LookupOption{
+			SuggestionLevel(CLOSEST),
+		},
+	}
+}
+
+// CheckOption is a function that controls how a CheckText is performed. An
+// error will be returned if the CheckOption is invalid.
+type CheckOption func(*checkParams) error
+
+// CheckLookupOpts allows the Lookup() options used for every word of the text
+// to be configured
+func CheckLookupOpts(opt ...LookupOption) CheckOption {
+	return func(cp *checkParams) error {
+		cp.lookupOptions = opt
+		return nil
+	}
+}
+
+// TextIssue is a word of a checked text that was not found in the dictionary.
+// Start and End are byte offsets into the text and RuneStart and RuneEnd are
+// rune offsets, so text[Start:End] is always the original Token.
+type TextIssue struct {
+	Start       int
+	End         int
+	RuneStart   int
+	RuneEnd     int
+	Token       string
+	Suggestions utils.SuggestionList
+}
+
+// CheckText tokenizes a text and looks up every word in it, skipping
+// punctuation and tokens without letters such as numbers. It returns an issue
+// for each word that is not in the dictionary, in the order they appear in
+// the text. By default the closest suggestions are returned for each issue.
+//
+// Accepts zero or more CheckOption that can be used to configure how the
+// check occurs
+func (model *SpellModel) CheckText(text string, opts ...CheckOption) ([]TextIssue, error) {
+	checkParams := model.defaultCheckParams()
+
+	for _, opt := range opts {
+		if err := opt(checkParams); err != nil {
+			return nil, err
+		}
+	}
+
+	lookupParams, err := model.newLookupParams(checkParams.lookupOptions)
+	if err != nil {
+		return nil, err
+	}
+	lookupParams.resetTruncated()
+	hasBigrams := model.bigrams.Has(lookupParams.dictOpts.Name)
+
+	issues := []TextIssue{}
+	tokens := utils.Tokenize(text)
+
+	for i, token := range tokens {
+		if !utils.HasLetter(token.Text) {
+			continue
+		}
+
+		lookupOptions := withLookupOptions(checkParams.lookupOptions, subLookup())
+
+		// When the dictionary has bigrams, words that are in the dictionary
+		// but unlikely between their neighbours are reported as well. Words
+		// across punctuation and tokens without letters aren't neighbours.
+		if hasBigrams {
+			previous, next := "", ""
+			if i > 0 && isNeighbour(tokens[i-1], text[tokens[i-1].End:token.Start]) {
+				previous = tokens[i-1].Text
+			}
+			if i < len(tokens)-1 && isNeighbour(tokens[i+1], text[token.End:tokens[i+1].Start]) {
+				next = tokens[i+1].Text
+			}
+			lookupOptions = withLookupOptions(lookupOptions, ContextWords(previous, next))
+		}
+
+		suggestions, err := model.Lookup(token.Text, lookupOptions...)
+		if err != nil {
+			return nil, err
+		}
+
+		if hasBigrams {
+			if len(suggestions) > 0 && suggestions[0].Distance == 0 {
+				continue
+			}
+		} else if hasExactMatch(suggestions) {
+			continue
+		}
+
+		issues = append(issues, TextIssue{
+			Start:       token.Start,
+			End:         token.End,
+			RuneStart:   token.RuneStart,
+			RuneEnd:     token.RuneEnd,
+			Token:       token.Text,
+			Suggestions: suggestions,
+		})
+	}
+
+	return issues, nil
+}
+
+// isNeighbour reports whether a token can be context for the word it is
+// separated from by gap, i.e. it has letters and gap has no punctuation ending
+// a sentence or clause
+func isNeighbour(token utils.Token, gap string) bool {
+	if !utils.HasLetter(token.Text) {
+		return false
+	}
+	for _, r := range gap {
+		if unicode.Is(unicode.Terminal_Punctuation, r) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasExactMatch(suggestions utils.SuggestionList) bool {
+	for _, suggestion := range suggestions {
+		if suggestion.Distance == 0 {
+			return true
+		}
+	}
+	return false
+}
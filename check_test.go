@@ -0,0 +1,107 @@
+package ta
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+func ExampleSpellModel_CheckText() {
+	s := NewSpellModel()
+
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "the"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 5, Word: "quick"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 5, Word: "fox"})
+
+	// Check a text and print the position of each issue
+	issues, _ := s.CheckText("The qiuck fox, the end.")
+	for _, issue := range issues {
+		fmt.Println(issue.Start, issue.End, issue.Token, issue.Suggestions)
+	}
+	// Output:
+	// 0 3 The [the]
+	// 4 9 qiuck [quick]
+	// 19 22 end []
+}
+
+func TestCheckText(t *testing.T) {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "café"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "don't"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "go"})
+
+	text := "«Don't» go to the cafe, 42 times!"
+	issues, err := s.CheckText(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		token              string
+		runeStart, runeEnd int
+	}{
+		{"Don't", 1, 6},
+		{"to", 11, 13},
+		{"the", 14, 17},
+		{"cafe", 18, 22},
+		{"times", 27, 32},
+	}
+
+	if len(issues) != len(want) {
+		t.Fatalf("Expected %d issues, got %d", len(want), len(issues))
+	}
+
+	runes := []rune(text)
+	for i, issue := range issues {
+		if issue.Token != want[i].token {
+			t.Errorf("Issue[%d]: expected token %q, got %q", i, want[i].token, issue.Token)
+		}
+		if text[issue.Start:issue.End] != issue.Token {
+			t.Errorf("Issue[%d]: byte offsets do not match the token", i)
+		}
+		if issue.RuneStart != want[i].runeStart || issue.RuneEnd != want[i].runeEnd {
+			t.Errorf("Issue[%d]: expected rune offsets %d-%d, got %d-%d", i,
+				want[i].runeStart, want[i].runeEnd, issue.RuneStart, issue.RuneEnd)
+		}
+		if string(runes[issue.RuneStart:issue.RuneEnd]) != issue.Token {
+			t.Errorf("Issue[%d]: rune offsets do not match the token", i)
+		}
+	}
+
+	if issues[3].Suggestions.String() != "[café]" {
+		t.Errorf("Expected [café], got %v", issues[3].Suggestions)
+	}
+}
+
+func TestCheckText_truncated(t *testing.T) {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Frequency: 1, Word: "example"})
+
+	// Only the lookup of the first word runs out of its time budget
+	slow := func(r1, r2 []rune, maxDist int) int {
+		if string(r1) == "exampel" || string(r2) == "exampel" {
+			time.Sleep(20 * time.Millisecond)
+		}
+		return utils.DamerauLevenshteinRunes(r1, r2, maxDist)
+	}
+
+	truncated := false
+	_, err := s.CheckText("exampel example", CheckLookupOpts(DistanceFunc(slow),
+		TimeBudget(10*time.Millisecond), Truncated(&truncated)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !truncated {
+		t.Fatal("Expected the check to be truncated")
+	}
+
+	_, err = s.CheckText("example", CheckLookupOpts(TimeBudget(time.Minute), Truncated(&truncated)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if truncated {
+		t.Fatal("Expected the check not to be truncated")
+	}
+}
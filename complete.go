@@ -0,0 +1,93 @@
+package ta
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+type completeParams struct {
+	dictOpts  *utils.DictOptions
+	tolerance uint32
+}
+
+func (model *SpellModel) defaultCompleteParams() *completeParams {
+	return &completeParams{
+		dictOpts: model.defaultDictOptions(),
+	}
+}
+
+// CompleteOption is a function that controls how completion is performed. An
+// error will be returned if the CompleteOption is invalid.
+type CompleteOption func(*completeParams) error
+
+// CompleteDictionaryOpts accepts multiple DictionaryOption and controls what
+// dictionary should be used during completion
+func CompleteDictionaryOpts(opts ...utils.DictionaryOption) CompleteOption {
+	return func(cp *completeParams) error {
+		for _, opt := range opts {
+			if err := opt(cp.dictOpts); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// CompleteTolerance allows the prefix to have up to edits typos, so that
+// "apl" completes to "apple". By default the prefix must match exactly.
+func CompleteTolerance(edits uint32) CompleteOption {
+	return func(cp *completeParams) error {
+		cp.tolerance = edits
+		return nil
+	}
+}
+
+// Complete returns the n most frequent words of the dictionary that start
+// with prefix. With a tolerance, words whose start is within the tolerance of
+// the prefix are also returned, ranked by the number of edits first. The
+// Distance of each suggestion is the number of edits made to the prefix.
+//
+// Accepts zero or more CompleteOption that can be used to configure how
+// completion occurs.
+func (model *SpellModel) Complete(prefix string, n int, opts ...CompleteOption) (utils.SuggestionList, error) {
+	completeParams := model.defaultCompleteParams()
+
+	for _, opt := range opts {
+		if err := opt(completeParams); err != nil {
+			return nil, err
+		}
+	}
+
+	if n < 1 {
+		return nil, errors.New("number of completions must be greater than 0")
+	}
+
+	matches := model.prefixes.Match(completeParams.dictOpts.Name,
+		model.normalize(prefix), int(completeParams.tolerance))
+
+	results := make(utils.SuggestionList, 0, len(matches))
+	for word, dist := range matches {
+		results = append(results, model.newDictSuggestion(word, dist, completeParams.dictOpts))
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		s1 := results[i]
+		s2 := results[j]
+
+		if s1.Distance != s2.Distance {
+			return s1.Distance < s2.Distance
+		}
+		if s1.Frequency != s2.Frequency {
+			return s1.Frequency > s2.Frequency
+		}
+		return s1.Word < s2.Word
+	})
+
+	if len(results) > n {
+		results = results[:n]
+	}
+
+	return results, nil
+}
@@ -0,0 +1,75 @@
+package ta
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+func newWithCompletions() *SpellModel {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Frequency: 50, Word: "apple"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 80, Word: "application"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 20, Word: "apply"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 90, Word: "banana"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "aplomb"})
+	return s
+}
+
+func ExampleSpellModel_Complete() {
+	s := newWithCompletions()
+
+	suggestions, _ := s.Complete("app", 2)
+	fmt.Println(suggestions)
+
+	// Allow a typo in the prefix
+	suggestions, _ = s.Complete("apl", 3, CompleteTolerance(1))
+	fmt.Println(suggestions)
+	// Output:
+	// [application, apple]
+	// [aplomb, application, apple]
+}
+
+func TestComplete(t *testing.T) {
+	s := newWithCompletions()
+	_, _ = s.AddEntry(utils.Entry{Frequency: 5, Word: "apricot"}, DictionaryName("fruit"))
+
+	suggestions, err := s.Complete("ap", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[application, apple, apply, aplomb]" {
+		t.Fatalf("Expected [application, apple, apply, aplomb], got %v", suggestions)
+	}
+
+	suggestions, err = s.Complete("ap", 10, CompleteDictionaryOpts(DictionaryName("fruit")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[apricot]" {
+		t.Fatalf("Expected [apricot], got %v", suggestions)
+	}
+
+	// Removed words are no longer completed
+	_, _ = s.RemoveEntry("application")
+	suggestions, err = s.Complete("app", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[apple, apply]" {
+		t.Fatalf("Expected [apple, apply], got %v", suggestions)
+	}
+
+	suggestions, err = s.Complete("xyz", 10, CompleteTolerance(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 0 {
+		t.Fatalf("Expected no completions, got %v", suggestions)
+	}
+
+	if _, err := s.Complete("app", 0); err == nil {
+		t.Fatal("Expected an error for n less than 1")
+	}
+}
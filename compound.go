@@ -0,0 +1,272 @@
+package ta
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"sync/atomic"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+type compoundParams struct {
+	lookupOptions []LookupOption
+}
+
+func (model *SpellModel) defaultCompoundParams() *compoundParams {
+	return &compoundParams{
+		lookupOptions: []LookupOption{
+			SuggestionLevel(BEST),
+		},
+	}
+}
+
+// CompoundOption is a function that controls how a LookupCompound is
+// performed. An error will be returned if the CompoundOption is invalid.
+type CompoundOption func(*compoundParams) error
+
+// CompoundLookupOpts allows the Lookup() options used for every token of the
+// compound lookup to be configured
+func CompoundLookupOpts(opt ...LookupOption) CompoundOption {
+	return func(cp *compoundParams) error {
+		cp.lookupOptions = opt
+		return nil
+	}
+}
+
+// CompoundResult holds the result of a call to LookupCompound()
+type CompoundResult struct {
+	// The distance between the input and the corrected string
+	Distance int
+	// One suggestion for each word of the corrected string. Words that are
+	// not in the dictionary have an Entry with no frequency.
+	Suggestions utils.SuggestionList
+}
+
+// GetWords returns a string slice of words for the result
+func (c CompoundResult) GetWords() []string {
+	return c.Suggestions.GetWords()
+}
+
+// String returns the corrected string
+func (c CompoundResult) String() string {
+	return strings.Join(c.GetWords(), " ")
+}
+
+// compoundPart is the correction chosen for one input term. It holds one
+// suggestion, or two when the term was split.
+type compoundPart struct {
+	suggestions utils.SuggestionList
+	distance    int
+	count       float64
+}
+
+// LookupCompound takes a multi-word input and returns the most likely
+// correction for the whole phrase. Besides correcting each word it will split
+// words that were wrongly joined and merge words that were wrongly split.
+//
+// Accepts zero or more CompoundOption that can be used to configure how the
+// lookup occurs
+func (model *SpellModel) LookupCompound(input string, opts ...CompoundOption) (*CompoundResult, error) {
+	compoundParams := model.defaultCompoundParams()
+
+	for _, opt := range opts {
+		if err := opt(compoundParams); err != nil {
+			return nil, err
+		}
+	}
+
+	lookupParams, err := model.newLookupParams(compoundParams.lookupOptions)
+	if err != nil {
+		return nil, err
+	}
+	lookupParams.resetTruncated()
+	dict := lookupParams.dictOpts.Name
+	hasBigrams := model.bigrams.Has(dict)
+
+	cumulativeFreq := float64(atomic.LoadUint64(&model.cumulativeFreq))
+	if cumulativeFreq == 0 {
+		return nil, errors.New("cumulative frequency is zero")
+	}
+
+	// The edit distance a term is looked up with
+	editDistance := func(term string) int {
+		return int(model.lookupEditDistance(model.normalize(term), lookupParams))
+	}
+
+	lookupOptions := withLookupOptions(compoundParams.lookupOptions, subLookup())
+
+	lookup := func(term string) (utils.SuggestionList, error) {
+		return model.Lookup(term, lookupOptions...)
+	}
+
+	// When the dictionary has bigrams, rank the corrections of each term by
+	// the word chosen before it
+	lookupAfter := func(term string, parts []compoundPart) (utils.SuggestionList, error) {
+		if !hasBigrams || len(parts) == 0 {
+			return lookup(term)
+		}
+		previous := parts[len(parts)-1].suggestions
+		return model.Lookup(term, withLookupOptions(lookupOptions,
+			ContextWords(previous[len(previous)-1].Word, ""))...)
+	}
+
+	distance := func(s1, s2 string, maxDist int) int {
+		return lookupParams.distanceFunction([]rune(model.normalize(s1)),
+			[]rune(model.normalize(s2)), maxDist)
+	}
+
+	// Unknown words get an edit distance larger than any correction and the
+	// same probability estimate that Segment uses
+	unknown := func(term string) compoundPart {
+		termLen := len([]rune(term))
+		return compoundPart{
+			suggestions: utils.SuggestionList{{
+				Distance: editDistance(term) + 1,
+				Entry:    utils.Entry{Word: term},
+			}},
+			distance: editDistance(term) + 1,
+			count:    10.0 / math.Pow(10.0, float64(termLen)),
+		}
+	}
+
+	known := func(suggestion utils.Suggestion) compoundPart {
+		return compoundPart{
+			suggestions: utils.SuggestionList{suggestion},
+			distance:    suggestion.Distance,
+			count:       float64(suggestion.Frequency),
+		}
+	}
+
+	terms := parseWords(input)
+	parts := make([]compoundPart, 0, len(terms))
+	lastCombi := false
+
+	for i, term := range terms {
+		suggestions, err := lookupAfter(term, parts)
+		if err != nil {
+			return nil, err
+		}
+
+		// Check whether the term and the previous one should be merged
+		if i > 0 && !lastCombi {
+			combi, err := lookup(terms[i-1] + term)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(combi) > 0 {
+				best1 := parts[len(parts)-1]
+				best2 := unknown(term)
+				if len(suggestions) > 0 {
+					best2 = known(suggestions[0])
+				}
+
+				// Merging costs one edit, the removed space
+				combiDist := combi[0].Distance + 1
+				distance1 := best1.distance + best2.distance
+				if combiDist < distance1 ||
+					(combiDist == distance1 &&
+						float64(combi[0].Frequency) > best1.count/cumulativeFreq*best2.count) {
+					combi[0].Distance = combiDist
+					parts[len(parts)-1] = known(combi[0])
+					lastCombi = true
+					continue
+				}
+			}
+		}
+		lastCombi = false
+
+		termLen := len([]rune(term))
+
+		// Never split terms that are in the dictionary or single characters
+		if len(suggestions) > 0 && (suggestions[0].Distance == 0 || termLen == 1) {
+			parts = append(parts, known(suggestions[0]))
+			continue
+		}
+
+		var best *compoundPart
+		if len(suggestions) > 0 {
+			part := known(suggestions[0])
+			best = &part
+		}
+
+		for j := 1; j < termLen; j++ {
+			suggestions1, err := lookup(utils.Substring(term, 0, j))
+			if err != nil {
+				return nil, err
+			}
+			if len(suggestions1) == 0 {
+				continue
+			}
+
+			suggestions2, err := lookup(utils.Substring(term, j, termLen))
+			if err != nil {
+				return nil, err
+			}
+			if len(suggestions2) == 0 {
+				continue
+			}
+
+			split := compoundPart{
+				suggestions: utils.SuggestionList{suggestions1[0], suggestions2[0]},
+			}
+
+			split.distance = distance(term, split.suggestions[0].Word+" "+split.suggestions[1].Word, editDistance(term))
+			if split.distance < 0 {
+				split.distance = editDistance(term) + 1
+			}
+
+			if best != nil {
+				if split.distance > best.distance {
+					continue
+				}
+				if split.distance < best.distance {
+					best = nil
+				}
+			}
+
+			if count, exists := model.bigrams.Load(dict,
+				model.normalize(suggestions1[0].Word), model.normalize(suggestions2[0].Word)); exists {
+				split.count = float64(count)
+			} else {
+				split.count = float64(suggestions1[0].Frequency) / cumulativeFreq *
+					float64(suggestions2[0].Frequency)
+			}
+
+			if best == nil || split.count > best.count {
+				best = &split
+			}
+		}
+
+		if best != nil {
+			parts = append(parts, *best)
+		} else {
+			parts = append(parts, unknown(term))
+		}
+	}
+
+	result := CompoundResult{
+		Suggestions: utils.SuggestionList{},
+	}
+	for _, part := range parts {
+		result.Suggestions = append(result.Suggestions, part.suggestions...)
+	}
+
+	corrected := result.String()
+	result.Distance = distance(input, corrected,
+		utils.Max(len([]rune(input)), len([]rune(corrected))))
+
+	return &result, nil
+}
+
+// parseWords splits the input into words, dropping whitespace and
+// punctuation
+func parseWords(input string) []string {
+	tokens := utils.Tokenize(input)
+	words := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		words = append(words, token.Text)
+	}
+	return words
+}
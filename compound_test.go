@@ -0,0 +1,90 @@
+package ta
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+func newWithPhrase() *SpellModel {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Frequency: 50, Word: "where"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 100, Word: "is"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 200, Word: "the"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 30, Word: "love"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 20, Word: "inspired"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 40, Word: "him"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 40, Word: "and"})
+	return s
+}
+
+func ExampleSpellModel_LookupCompound() {
+	s := NewSpellModel()
+
+	_, _ = s.AddEntry(utils.Entry{Frequency: 50, Word: "where"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 100, Word: "is"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 200, Word: "the"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 30, Word: "love"})
+
+	// Correct a phrase with joined words, split words and typos
+	result, _ := s.LookupCompound("whereis th elove")
+	fmt.Println(result)
+	// Output:
+	// where is the love
+}
+
+func TestLookupCompound(t *testing.T) {
+	s := newWithPhrase()
+
+	tests := []struct {
+		input    string
+		want     string
+		distance int
+	}{
+		{"whereis th elove", "where is the love", 2},
+		{"where is the love", "where is the love", 0},
+		{"and ins pired him", "and inspired him", 1},
+		{"the lvoe", "the love", 1},
+	}
+
+	for i, test := range tests {
+		result, err := s.LookupCompound(test.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.String() != test.want {
+			t.Errorf("Test[%d]: LookupCompound(%q) returned %q, want %q",
+				i, test.input, result.String(), test.want)
+		}
+		if result.Distance != test.distance {
+			t.Errorf("Test[%d]: LookupCompound(%q) distance is %v, want %v",
+				i, test.input, result.Distance, test.distance)
+		}
+		if len(result.Suggestions) != len(result.GetWords()) {
+			t.Errorf("Test[%d]: expected one suggestion per word", i)
+		}
+	}
+}
+
+func TestLookupCompound_unknownWord(t *testing.T) {
+	s := newWithPhrase()
+
+	result, err := s.LookupCompound("the zzzzzz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.String() != "the zzzzzz" {
+		t.Fatalf("Expected unknown word to be kept, got %q", result.String())
+	}
+	if result.Suggestions[1].Frequency != 0 {
+		t.Fatal("Expected unknown word to have no frequency")
+	}
+}
+
+func TestLookupCompound_emptyModel(t *testing.T) {
+	s := NewSpellModel()
+	if _, err := s.LookupCompound("whereis"); err == nil {
+		t.Fatal("Expected an error for an empty model")
+	}
+}
@@ -0,0 +1,247 @@
+package ta
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+type corpusParams struct {
+	dictionaryOptions []utils.DictionaryOption
+	lowercase         bool
+	maxVocabulary     int
+	minCount          uint64
+}
+
+func (model *SpellModel) defaultCorpusParams() *corpusParams {
+	return &corpusParams{
+		minCount: 1,
+	}
+}
+
+// CorpusOption is a function that controls how a dictionary is built from a
+// corpus. An error will be returned if the CorpusOption is invalid.
+type CorpusOption func(*corpusParams) error
+
+// CorpusDictionaryOpts accepts multiple DictionaryOption and controls what
+// dictionary the words are added to and how
+func CorpusDictionaryOpts(opts ...utils.DictionaryOption) CorpusOption {
+	return func(cp *corpusParams) error {
+		cp.dictionaryOptions = append(cp.dictionaryOptions, opts...)
+		return nil
+	}
+}
+
+// CorpusLowercase defines whether words are lowercased before they are
+// counted, so that "The" and "the" count as the same word
+func CorpusLowercase(enabled bool) CorpusOption {
+	return func(cp *corpusParams) error {
+		cp.lowercase = enabled
+		return nil
+	}
+}
+
+// CorpusMinCount sets how many times a word must occur in the corpus to be
+// added, e.g. to leave out typos that only occur once. Words that share a
+// normalized key, see SetNormalizer, are counted together.
+func CorpusMinCount(n uint64) CorpusOption {
+	return func(cp *corpusParams) error {
+		if n < 1 {
+			return errors.New("min count must be greater than 0")
+		}
+		cp.minCount = n
+		return nil
+	}
+}
+
+// CorpusMaxVocabulary limits the words added to the n most frequent ones
+func CorpusMaxVocabulary(n int) CorpusOption {
+	return func(cp *corpusParams) error {
+		if n < 1 {
+			return errors.New("max vocabulary must be greater than 0")
+		}
+		cp.maxVocabulary = n
+		return nil
+	}
+}
+
+func (model *SpellModel) newCorpusParams(opts []CorpusOption) (*corpusParams, error) {
+	corpusParams := model.defaultCorpusParams()
+
+	for _, opt := range opts {
+		if err := opt(corpusParams); err != nil {
+			return nil, err
+		}
+	}
+
+	// Report invalid dictionary options once rather than for every word
+	dictOpts := model.defaultDictOptions()
+	for _, opt := range corpusParams.dictionaryOptions {
+		if err := opt(dictOpts); err != nil {
+			return nil, err
+		}
+	}
+
+	return corpusParams, nil
+}
+
+// BuildDictionary counts the words of a plain text corpus and adds them to a
+// dictionary, with the number of times they occur as their frequency. Words
+// are split as in CheckText, and tokens without a letter, such as numbers,
+// are left out. Merges with any dictionary data already loaded.
+//
+// Accepts zero or more CorpusOption that can be used to configure how the
+// dictionary is built.
+func (model *SpellModel) BuildDictionary(r io.Reader, opts ...CorpusOption) (*ImportReport, error) {
+	corpusParams, err := model.newCorpusParams(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]uint64)
+	if err := corpusParams.count(r, counts); err != nil {
+		return nil, err
+	}
+
+	return model.addCounts(counts, corpusParams)
+}
+
+// BuildDictionaryFromDir is the same as BuildDictionary but counts the words
+// of every file in a directory and its subdirectories
+func (model *SpellModel) BuildDictionaryFromDir(dir string, opts ...CorpusOption) (*ImportReport, error) {
+	corpusParams, err := model.newCorpusParams(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]uint64)
+
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() {
+			return err
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		if err := corpusParams.count(f, counts); err != nil {
+			return &ImportError{Path: path, Err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return model.addCounts(counts, corpusParams)
+}
+
+// count adds the number of times each word occurs in r to counts
+func (cp *corpusParams) count(r io.Reader, counts map[string]uint64) error {
+	// Text is read a whitespace separated word at a time, so that long lines
+	// don't matter
+	s := bufio.NewScanner(r)
+	s.Split(scanCorpusWords)
+
+	for s.Scan() {
+		for _, token := range utils.Tokenize(s.Text()) {
+			if !utils.HasLetter(token.Text) {
+				continue
+			}
+
+			word := token.Text
+			if cp.lowercase {
+				word = strings.ToLower(word)
+			}
+			counts[word]++
+		}
+	}
+
+	return s.Err()
+}
+
+// scanCorpusWords is bufio.ScanWords, except that text without whitespace
+// that fills the buffer of the scanner is returned as it is rather than
+// failing, since it is too long to be a word anyway
+func scanCorpusWords(data []byte, atEOF bool) (int, []byte, error) {
+	advance, token, err := bufio.ScanWords(data, atEOF)
+	if advance == 0 && token == nil && err == nil && len(data) >= bufio.MaxScanTokenSize {
+		return len(data), data, nil
+	}
+	return advance, token, err
+}
+
+// addCounts adds the words that were counted often enough to the dictionary,
+// the most frequent first. Words that share a normalized key are added to the
+// same entry, so they are counted together against the min count and the max
+// vocabulary.
+func (model *SpellModel) addCounts(counts map[string]uint64, corpusParams *corpusParams) (*ImportReport, error) {
+	report := &ImportReport{Read: len(counts)}
+
+	totals := make(map[string]uint64)
+	for word, count := range counts {
+		totals[model.normalize(word)] += count
+	}
+
+	keys := make([]string, 0, len(totals))
+	for key, total := range totals {
+		if total >= corpusParams.minCount {
+			keys = append(keys, key)
+		}
+	}
+	sortByCount(keys, totals)
+
+	if corpusParams.maxVocabulary > 0 && len(keys) > corpusParams.maxVocabulary {
+		keys = keys[:corpusParams.maxVocabulary]
+	}
+
+	kept := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		kept[key] = struct{}{}
+	}
+
+	words := make([]string, 0, len(keys))
+	for word := range counts {
+		if _, exists := kept[model.normalize(word)]; exists {
+			words = append(words, word)
+		}
+	}
+	// The most frequent spelling of a key is added first, so it is the one
+	// kept
+	sortByCount(words, counts)
+
+	for _, word := range words {
+		added, err := model.AddEntry(utils.Entry{
+			Frequency: counts[word],
+			Word:      word,
+		}, corpusParams.dictionaryOptions...)
+		if err != nil {
+			return report, err
+		}
+		if added {
+			report.Added++
+		}
+	}
+
+	return report, nil
+}
+
+// sortByCount sorts words by their count, the largest first, and then
+// alphabetically
+func sortByCount(words []string, counts map[string]uint64) {
+	sort.Slice(words, func(i, j int) bool {
+		if counts[words[i]] != counts[words[j]] {
+			return counts[words[i]] > counts[words[j]]
+		}
+		return words[i] < words[j]
+	})
+}
@@ -0,0 +1,133 @@
+package ta
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+const testCorpus = `The cluster restarts the pods. The pods of the cluster
+are scheduled by the scheduler; 3 pods restart in 2 minutes.
+Clusters with one pod don't restart.`
+
+func ExampleSpellModel_BuildDictionary() {
+	s := NewSpellModel()
+
+	_, _ = s.BuildDictionary(strings.NewReader(testCorpus),
+		CorpusLowercase(true), CorpusMinCount(2))
+
+	suggestions, _ := s.Lookup("clustr")
+	fmt.Println(suggestions, suggestions[0].Frequency)
+	// Output:
+	// [cluster] 2
+}
+
+func TestBuildDictionary(t *testing.T) {
+	s := NewSpellModel()
+
+	report, err := s.BuildDictionary(strings.NewReader(testCorpus),
+		CorpusLowercase(true), CorpusMaxVocabulary(3),
+		CorpusDictionaryOpts(DictionaryName("k8s")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Read != 17 || report.Added != 3 {
+		t.Fatalf("Expected 17 read and 3 added, got %+v", report)
+	}
+
+	expected := map[string]uint64{"the": 5, "pods": 3, "cluster": 2}
+	for word, count := range expected {
+		entry, _ := s.GetEntry(word, DictionaryName("k8s"))
+		if entry == nil || entry.Frequency != count {
+			t.Fatalf("Expected %s with frequency %d, got %+v", word, count, entry)
+		}
+	}
+	if s.cumulativeFreq != 10 {
+		t.Fatalf("Expected cumulative frequency 10, got %d", s.cumulativeFreq)
+	}
+
+	// Without lowercasing "The" is counted apart from "the", and numbers are
+	// never words
+	s = NewSpellModel()
+	if _, err := s.BuildDictionary(strings.NewReader(testCorpus)); err != nil {
+		t.Fatal(err)
+	}
+	if entry, _ := s.GetEntry("The"); entry == nil || entry.Frequency != 2 {
+		t.Fatalf("Expected The with frequency 2, got %+v", entry)
+	}
+	if entry, _ := s.GetEntry("3"); entry != nil {
+		t.Fatal("Expected numbers to be left out")
+	}
+	if entry, _ := s.GetEntry("don't"); entry == nil {
+		t.Fatal("Expected contractions to be kept")
+	}
+
+	if _, err := s.BuildDictionary(strings.NewReader(""), CorpusMinCount(0)); err == nil {
+		t.Fatal("Expected an error for a min count of 0")
+	}
+}
+
+func TestBuildDictionary_longLines(t *testing.T) {
+	// A line of a few megabytes, and text without whitespace longer than
+	// the buffer of a scanner
+	corpus := strings.Repeat("spelling is hard ", 200000) + "\n" +
+		strings.Repeat("x", 100000) + " spelling"
+
+	s := NewSpellModel()
+	if _, err := s.BuildDictionary(strings.NewReader(corpus)); err != nil {
+		t.Fatal(err)
+	}
+	if entry, _ := s.GetEntry("spelling"); entry == nil || entry.Frequency != 200001 {
+		t.Fatalf("Expected spelling with frequency 200001, got %+v", entry)
+	}
+}
+
+func TestBuildDictionary_normalizer(t *testing.T) {
+	s := NewSpellModel()
+	_ = s.SetNormalizer(&utils.Normalizer{CaseFold: true})
+
+	// The and the only reach the min count together
+	report, err := s.BuildDictionary(strings.NewReader("The cat saw the dog"), CorpusMinCount(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Added != 1 {
+		t.Fatalf("Expected 1 added, got %+v", report)
+	}
+	if entry, _ := s.GetEntry("the"); entry == nil || entry.Frequency != 2 {
+		t.Fatalf("Expected the with frequency 2, got %+v", entry)
+	}
+}
+
+func TestBuildDictionaryFromDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "corpus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	_ = os.Mkdir(filepath.Join(dir, "docs"), 0755)
+	_ = ioutil.WriteFile(filepath.Join(dir, "a.txt"), []byte("spelling is hard"), 0644)
+	_ = ioutil.WriteFile(filepath.Join(dir, "docs", "b.txt"), []byte("spelling checks spelling"), 0644)
+
+	s := NewSpellModel()
+	report, err := s.BuildDictionaryFromDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Read != 4 {
+		t.Fatalf("Expected 4 words read, got %+v", report)
+	}
+	if entry, _ := s.GetEntry("spelling"); entry == nil || entry.Frequency != 3 {
+		t.Fatalf("Expected spelling with frequency 3, got %+v", entry)
+	}
+
+	if _, err := s.BuildDictionaryFromDir(filepath.Join(dir, "missing")); err == nil {
+		t.Fatal("Expected an error for a missing directory")
+	}
+}
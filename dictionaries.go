@@ -0,0 +1,126 @@
+package ta
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+// DictionaryWeight is a dictionary to look up and the multiplier applied to
+// the frequencies of its words
+type DictionaryWeight struct {
+	Name   string
+	Weight float64
+}
+
+// Dictionaries looks up the input in several dictionaries at once, instead of
+// the dictionary set with DictionaryOpts. Suggestions are ranked as if the
+// frequency of each word was multiplied by the weight of its dictionary, so
+// that words of a dictionary with a higher weight rank first among words at the
+// same distance, but they keep the frequency of their dictionary. Results are
+// merged so that each word is only suggested once, from the dictionary where
+// it ranks best, see Suggestion.Dictionary.
+func Dictionaries(dicts ...DictionaryWeight) LookupOption {
+	return func(lp *lookupParams) error {
+		if len(dicts) == 0 {
+			return errors.New("at least one dictionary must be given")
+		}
+		for _, dict := range dicts {
+			if dict.Weight <= 0 {
+				return errors.New("dictionary weight must be greater than 0")
+			}
+		}
+		lp.dictionaries = dicts
+		return nil
+	}
+}
+
+// lookupDictionaries looks up the input in each dictionary of the lookup
+// params and merges the results
+func (model *SpellModel) lookupDictionaries(input string, lookupParams *lookupParams) (utils.SuggestionList, error) {
+	ranked := lookupParams.context != nil || lookupParams.noisyChannel
+
+	var merged utils.SuggestionList
+	var weights []float64
+
+	for _, dict := range lookupParams.dictionaries {
+		dictOpts := *lookupParams.dictOpts
+		dictOpts.Name = dict.Name
+
+		dictParams := *lookupParams
+		dictParams.dictOpts = &dictOpts
+
+		results, err := model.lookupDictionary(input, &dictParams)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, result := range results {
+			merged = append(merged, result)
+			weights = append(weights, dict.Weight)
+		}
+	}
+
+	if len(merged) == 0 {
+		return merged, nil
+	}
+
+	if ranked {
+		// Ranked suggestions keep their probability, weighted for the merge
+		order := make([]int, len(merged))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			return merged[order[i]].Probability*weights[order[i]] >
+				merged[order[j]].Probability*weights[order[j]]
+		})
+
+		sorted := make(utils.SuggestionList, len(merged))
+		for i, j := range order {
+			sorted[i] = merged[j]
+		}
+		merged = sorted
+	} else {
+		// The sort sees the weighted frequencies, and the suggestions are then
+		// put back with their own, found by dictionary and word
+		weighted := make(utils.SuggestionList, len(merged))
+		originals := make(map[[2]string]utils.Suggestion, len(merged))
+
+		for i, suggestion := range merged {
+			originals[[2]string{suggestion.Dictionary, suggestion.Word}] = suggestion
+			suggestion.Frequency = uint64(float64(suggestion.Frequency) * weights[i])
+			weighted[i] = suggestion
+		}
+
+		lookupParams.sortFunc(weighted)
+
+		for i, suggestion := range weighted {
+			merged[i] = originals[[2]string{suggestion.Dictionary, suggestion.Word}]
+		}
+	}
+
+	// Keep the first of the suggestions of each word
+	found := make(map[string]struct{}, len(merged))
+	results := merged[:0]
+
+	for _, suggestion := range merged {
+		if !utils.AddKey(found, model.normalize(suggestion.Word)) {
+			continue
+		}
+		if lookupParams.suggestionLevel == CLOSEST && suggestion.Distance != merged[0].Distance {
+			continue
+		}
+		results = append(results, suggestion)
+	}
+
+	switch {
+	case lookupParams.suggestionLevel == BEST:
+		results = results[:1]
+	case lookupParams.maxResults > 0 && len(results) > lookupParams.maxResults:
+		results = results[:lookupParams.maxResults]
+	}
+
+	return results, nil
+}
@@ -0,0 +1,100 @@
+package ta
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+func newWithDictionaries() *SpellModel {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Frequency: 100, Word: "cluster"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 50, Word: "clusters"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 5, Word: "kubectl"}, DictionaryName("domain"))
+	_, _ = s.AddEntry(utils.Entry{Frequency: 30, Word: "clustr"}, DictionaryName("domain"))
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "cluster"}, DictionaryName("customer"))
+	return s
+}
+
+func ExampleDictionaries() {
+	s := newWithDictionaries()
+
+	suggestions, _ := s.Lookup("kubctl", Dictionaries(
+		DictionaryWeight{Name: "default", Weight: 1},
+		DictionaryWeight{Name: "domain", Weight: 2},
+	))
+	fmt.Println(suggestions, suggestions[0].Dictionary)
+	// Output:
+	// [kubectl] domain
+}
+
+func TestLookup_dictionaries(t *testing.T) {
+	s := newWithDictionaries()
+
+	dicts := Dictionaries(
+		DictionaryWeight{Name: "default", Weight: 1},
+		DictionaryWeight{Name: "domain", Weight: 2},
+		DictionaryWeight{Name: "customer", Weight: 20},
+	)
+
+	suggestions, err := s.Lookup("clustre", dicts, SuggestionLevel(ALL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[cluster, clustr, clusters]" {
+		t.Fatalf("Expected [cluster, clustr, clusters], got %v", suggestions)
+	}
+
+	// Duplicates keep the suggestion that ranks best once weighted, with the
+	// frequency of its dictionary
+	if suggestions[0].Dictionary != "customer" || suggestions[0].Frequency != 10 {
+		t.Fatalf("Expected cluster from customer with frequency 10, got %+v", suggestions[0])
+	}
+	if suggestions[1].Dictionary != "domain" || suggestions[1].Frequency != 30 {
+		t.Fatalf("Expected clustr from domain with frequency 30, got %+v", suggestions[1])
+	}
+
+	// A weight below 1 ranks words lower without losing their frequency
+	suggestions, err = s.Lookup("kubctl", SuggestionLevel(ALL), Dictionaries(
+		DictionaryWeight{Name: "domain", Weight: 0.01},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[kubectl]" || suggestions[0].Frequency != 5 {
+		t.Fatalf("Expected kubectl with frequency 5, got %+v", suggestions)
+	}
+
+	suggestions, err = s.Lookup("clustre", dicts, SuggestionLevel(CLOSEST))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[cluster, clustr]" {
+		t.Fatalf("Expected [cluster, clustr], got %v", suggestions)
+	}
+
+	suggestions, err = s.Lookup("clustre", dicts, SuggestionLevel(ALL), MaxResults(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[cluster]" {
+		t.Fatalf("Expected [cluster], got %v", suggestions)
+	}
+
+	// A single dictionary lookup also reports its dictionary
+	suggestions, err = s.Lookup("clustre")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions[0].Dictionary != "default" {
+		t.Fatalf("Expected dictionary default, got %s", suggestions[0].Dictionary)
+	}
+
+	if _, err := s.Lookup("clustre", Dictionaries()); err == nil {
+		t.Fatal("Expected an error without dictionaries")
+	}
+	if _, err := s.Lookup("clustre", Dictionaries(DictionaryWeight{Name: "default"})); err == nil {
+		t.Fatal("Expected an error for a zero weight")
+	}
+}
@@ -0,0 +1,346 @@
+package ta
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+type exportParams struct {
+	dictOpts  *utils.DictOptions
+	separator rune
+}
+
+func (model *SpellModel) defaultExportParams() *exportParams {
+	return &exportParams{
+		dictOpts:  model.defaultDictOptions(),
+		separator: ',',
+	}
+}
+
+// ExportOption is a function that controls how a dictionary is exported. An
+// error will be returned if the ExportOption is invalid.
+type ExportOption func(*exportParams) error
+
+// ExportDictionaryOpts accepts multiple DictionaryOption and controls what
+// dictionary is exported
+func ExportDictionaryOpts(opts ...utils.DictionaryOption) ExportOption {
+	return func(ep *exportParams) error {
+		for _, opt := range opts {
+			if err := opt(ep.dictOpts); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// ExportSeparator sets the separator between the columns of a CSV file, which
+// is a comma by default, e.g. "\t" for TSV
+func ExportSeparator(separator string) ExportOption {
+	return func(ep *exportParams) error {
+		comma, err := csvSeparator(separator)
+		if err != nil {
+			return err
+		}
+		ep.separator = comma
+		return nil
+	}
+}
+
+func (model *SpellModel) newExportParams(opts []ExportOption) (*exportParams, error) {
+	exportParams := model.defaultExportParams()
+
+	for _, opt := range opts {
+		if err := opt(exportParams); err != nil {
+			return nil, err
+		}
+	}
+
+	return exportParams, nil
+}
+
+// csvSeparator returns the separator of a CSV file as a rune
+func csvSeparator(separator string) (rune, error) {
+	runes := []rune(separator)
+	if len(runes) != 1 {
+		return 0, errors.New("CSV separator must be a single character")
+	}
+	return runes[0], nil
+}
+
+// ImportCSV adds the entries of a CSV file, or of a TSV file with
+// ImportSeparator("\t"), to a dictionary. The first row is a header. The word
+// and its frequency are in the columns set with ImportColumns, by default the
+// first and the second. Every other column is stored in WordData under its
+// header, see ImportHeader, with numbers and booleans converted from text
+// unless they are in double quotes, see utils.ParseValue. Malformed rows are
+// skipped unless ImportStopOnError is set.
+//
+// Accepts zero or more ImportOption that can be used to configure how the
+// import occurs.
+func (model *SpellModel) ImportCSV(r io.Reader, opts ...ImportOption) (*ImportReport, error) {
+	importParams, err := model.newImportParams(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	if importParams.separator != "" {
+		if reader.Comma, err = csvSeparator(importParams.separator); err != nil {
+			return nil, err
+		}
+	}
+	if importParams.comment != "" {
+		comment := []rune(importParams.comment)
+		if len(comment) != 1 {
+			return nil, errors.New("CSV comment must be a single character")
+		}
+		reader.Comment = comment[0]
+	}
+
+	report := &ImportReport{}
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return report, nil
+	}
+	if err != nil {
+		return nil, &ImportError{Line: 1, Err: err}
+	}
+
+	keys := importParams.headerKeys(header)
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			if err := importParams.malformed(report, "", parseErr.Line, parseErr.Err); err != nil {
+				return report, err
+			}
+			continue
+		}
+		if err != nil {
+			return report, err
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		entry, err := importParams.parseColumns(record, keys, utils.ParseValue)
+		if err != nil {
+			if err := importParams.malformed(report, "", line, err); err != nil {
+				return report, err
+			}
+			continue
+		}
+		report.Read++
+
+		added, err := model.AddEntry(entry, importParams.dictionaryOptions...)
+		if err != nil {
+			return report, err
+		}
+		if added {
+			report.Added++
+		}
+	}
+
+	return report, nil
+}
+
+// headerKeys returns the WordData keys of the columns of a CSV file
+func (ip *importParams) headerKeys(header []string) map[int]string {
+	keys := make(map[int]string)
+
+	for column, name := range header {
+		if column == ip.termColumn || column == ip.countColumn {
+			continue
+		}
+
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF"))
+		if key, exists := ip.dataColumns[column]; exists {
+			name = key
+		} else if key, exists := ip.header[name]; exists {
+			name = key
+		}
+
+		if name != "" {
+			keys[column] = name
+		}
+	}
+
+	return keys
+}
+
+// ExportCSV writes the entries of a dictionary as a CSV file that ImportCSV
+// reads back. The header holds the word, its frequency and every key of
+// WordData, and entries are sorted by word.
+//
+// Accepts zero or more ExportOption that can be used to configure how the
+// export occurs.
+func (model *SpellModel) ExportCSV(w io.Writer, opts ...ExportOption) error {
+	exportParams, err := model.newExportParams(opts)
+	if err != nil {
+		return err
+	}
+
+	entries := model.sortedEntries(exportParams.dictOpts.Name)
+
+	found := make(map[string]struct{})
+	var keys []string
+	for _, entry := range entries {
+		for key := range entry.WordData {
+			if utils.AddKey(found, key) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	writer := csv.NewWriter(w)
+	writer.Comma = exportParams.separator
+
+	if err := writer.Write(append([]string{"word", "frequency"}, keys...)); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		record := make([]string, 0, len(keys)+2)
+		record = append(record, entry.Word, strconv.FormatUint(entry.Frequency, 10))
+
+		for _, key := range keys {
+			value := ""
+			if v, exists := entry.WordData[key]; exists {
+				value = utils.FormatValue(v)
+			}
+			record = append(record, value)
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ImportJSONL adds the entries of a JSON Lines file to a dictionary. Each line
+// holds an entry as a JSON object, e.g. {"Word": "cat", "Frequency": 10,
+// "WordData": {"pos": "noun"}}. Keys of WordData may be renamed with
+// ImportHeader. Blank lines are skipped, and so are malformed lines unless
+// ImportStopOnError is set.
+//
+// Accepts zero or more ImportOption that can be used to configure how the
+// import occurs.
+func (model *SpellModel) ImportJSONL(r io.Reader, opts ...ImportOption) (*ImportReport, error) {
+	importParams, err := model.newImportParams(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{}
+
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1024*1024)
+	line := 0
+
+	for s.Scan() {
+		line++
+
+		text := strings.TrimSpace(s.Text())
+		if text == "" {
+			continue
+		}
+
+		entry := utils.Entry{}
+		if err := json.Unmarshal([]byte(text), &entry); err != nil {
+			if err := importParams.malformed(report, "", line, err); err != nil {
+				return report, err
+			}
+			continue
+		}
+		if entry.Word == "" {
+			if err := importParams.malformed(report, "", line, errors.New("missing word")); err != nil {
+				return report, err
+			}
+			continue
+		}
+		report.Read++
+
+		if importParams.header != nil && entry.WordData != nil {
+			wordData := utils.WordData{}
+			for key, value := range entry.WordData {
+				if renamed, exists := importParams.header[key]; exists {
+					key = renamed
+				}
+				if key != "" {
+					wordData[key] = value
+				}
+			}
+			entry.WordData = wordData
+		}
+
+		added, err := model.AddEntry(entry, importParams.dictionaryOptions...)
+		if err != nil {
+			return report, err
+		}
+		if added {
+			report.Added++
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return report, &ImportError{Line: line, Err: err}
+	}
+
+	return report, nil
+}
+
+// ExportJSONL writes the entries of a dictionary as a JSON Lines file that
+// ImportJSONL reads back, sorted by word
+//
+// Accepts zero or more ExportOption that can be used to configure how the
+// export occurs.
+func (model *SpellModel) ExportJSONL(w io.Writer, opts ...ExportOption) error {
+	exportParams, err := model.newExportParams(opts)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	for _, entry := range model.sortedEntries(exportParams.dictOpts.Name) {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sortedEntries returns the entries of a dictionary sorted by word
+func (model *SpellModel) sortedEntries(dict string) []utils.Entry {
+	words := model.library.Words(dict)
+	sort.Strings(words)
+
+	entries := make([]utils.Entry, 0, len(words))
+	for _, word := range words {
+		if entry, exists := model.library.Load(dict, word); exists {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
@@ -0,0 +1,174 @@
+package ta
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+const testCSV = `Word,Count,Part of speech,Syllables,Plural,Notes
+colour,45,noun,2,false,
+"honour, n.",30,noun,2,false,"formal, British"
+run,x,verb,1,false,
+runs,12,verb,1,true,
+`
+
+func ExampleSpellModel_ExportCSV() {
+	s := NewSpellModel()
+
+	_, _ = s.ImportCSV(strings.NewReader("word,count,pos\ncolour,45,noun\nrun,30,verb\n"))
+
+	_ = s.ExportCSV(os.Stdout, ExportSeparator("\t"))
+	// Output:
+	// word	frequency	pos
+	// colour	45	noun
+	// run	30	verb
+}
+
+func TestImportCSV(t *testing.T) {
+	s := NewSpellModel()
+	report, err := s.ImportCSV(strings.NewReader(testCSV),
+		ImportHeader(map[string]string{"Part of speech": "pos", "Notes": ""}),
+		ImportDictionaryOpts(DictionaryName("en")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Read != 3 || report.Added != 3 {
+		t.Fatalf("Expected 3 read and 3 added, got %+v", report)
+	}
+	if len(report.Skipped) != 1 || report.Skipped[0].Line != 4 {
+		t.Fatalf("Expected line 4 to be skipped, got %v", report.Skipped)
+	}
+
+	entry, _ := s.GetEntry("honour, n.", DictionaryName("en"))
+	expected := utils.WordData{"pos": "noun", "Syllables": float64(2), "Plural": false}
+	if entry == nil || entry.Frequency != 30 || !reflect.DeepEqual(entry.WordData, expected) {
+		t.Fatalf("Expected honour with typed word data, got %+v", entry)
+	}
+
+	_, err = s.ImportCSV(strings.NewReader(testCSV), ImportStopOnError(true))
+	var importErr *ImportError
+	if !errors.As(err, &importErr) || importErr.Line != 4 {
+		t.Fatalf("Expected an import error at line 4, got %v", err)
+	}
+
+	if _, err := s.ImportCSV(strings.NewReader(testCSV), ImportSeparator("->")); err == nil {
+		t.Fatal("Expected an error for a separator of two characters")
+	}
+}
+
+func TestImportCSV_lines(t *testing.T) {
+	csv := "word,count,note\n" +
+		"# a comment\n" +
+		"\n" +
+		"colour,45,\"spans\r\ntwo lines\"\n" +
+		"honour,x,\n" +
+		"\"multi\nline\",3,\"and\nmore\"\n" +
+		"run,y"
+
+	s := NewSpellModel()
+	report, err := s.ImportCSV(strings.NewReader(csv), ImportComment("#"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []int
+	for _, skipped := range report.Skipped {
+		lines = append(lines, skipped.Line)
+	}
+	if report.Read != 2 || fmt.Sprint(lines) != "[6 10]" {
+		t.Fatalf("Expected 2 read and lines 6 and 10 skipped, got %+v", report)
+	}
+}
+
+func TestExportCSV_roundTrip(t *testing.T) {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Word: "colour", Frequency: 45,
+		WordData: utils.WordData{"pos": "noun", "syllables": float64(2)}}, DictionaryName("en"))
+	_, _ = s.AddEntry(utils.Entry{Word: "runs", Frequency: 12,
+		WordData: utils.WordData{"pos": "verb", "plural": true, "note": "a, b"}}, DictionaryName("en"))
+	// Strings that look like numbers or booleans stay strings
+	_, _ = s.AddEntry(utils.Entry{Word: "agent", Frequency: 7,
+		WordData: utils.WordData{"code": "007", "note": "1e3", "plural": "false", "pos": `"noun"`}}, DictionaryName("en"))
+	_, _ = s.AddEntry(utils.Entry{Word: "other", Frequency: 1})
+
+	for _, separator := range []string{",", "\t"} {
+		var buf bytes.Buffer
+		if err := s.ExportCSV(&buf, ExportSeparator(separator),
+			ExportDictionaryOpts(DictionaryName("en"))); err != nil {
+			t.Fatal(err)
+		}
+
+		imported := NewSpellModel()
+		if _, err := imported.ImportCSV(&buf, ImportSeparator(separator),
+			ImportDictionaryOpts(DictionaryName("en"))); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(s.sortedEntries("en"), imported.sortedEntries("en")) {
+			t.Fatalf("Separator %q: expected %v, got %v",
+				separator, s.sortedEntries("en"), imported.sortedEntries("en"))
+		}
+	}
+}
+
+func TestImportJSONL(t *testing.T) {
+	jsonl := `{"Word": "colour", "Frequency": 45, "WordData": {"Part of speech": "noun", "syllables": 2}}
+
+{"Word": "", "Frequency": 1}
+{"Word": "run",
+{"Word": "run", "Frequency": 30}
+`
+
+	s := NewSpellModel()
+	report, err := s.ImportJSONL(strings.NewReader(jsonl),
+		ImportHeader(map[string]string{"Part of speech": "pos"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Read != 2 || report.Added != 2 {
+		t.Fatalf("Expected 2 read and 2 added, got %+v", report)
+	}
+
+	var lines []int
+	for _, skipped := range report.Skipped {
+		lines = append(lines, skipped.Line)
+	}
+	if fmt.Sprint(lines) != "[3 4]" {
+		t.Fatalf("Expected lines 3 and 4 to be skipped, got %v", report.Skipped)
+	}
+
+	entry, _ := s.GetEntry("colour")
+	expected := utils.WordData{"pos": "noun", "syllables": float64(2)}
+	if entry == nil || !reflect.DeepEqual(entry.WordData, expected) {
+		t.Fatalf("Expected colour with renamed word data, got %+v", entry)
+	}
+}
+
+func TestExportJSONL_roundTrip(t *testing.T) {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Word: "colour", Frequency: 45,
+		WordData: utils.WordData{"pos": "noun", "forms": []interface{}{"colours"}}})
+	_, _ = s.AddEntry(utils.Entry{Word: "run", Frequency: 30})
+
+	var buf bytes.Buffer
+	if err := s.ExportJSONL(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	imported := NewSpellModel()
+	if _, err := imported.ImportJSONL(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	dict := s.defaultDictOptions().Name
+	if !reflect.DeepEqual(s.sortedEntries(dict), imported.sortedEntries(dict)) {
+		t.Fatalf("Expected %v, got %v", s.sortedEntries(dict), imported.sortedEntries(dict))
+	}
+}
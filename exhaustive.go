@@ -0,0 +1,75 @@
+package ta
+
+import (
+	"github.com/agusnavce/ta/utils"
+)
+
+// LookupExhaustive is the same as Lookup but compares the input with every
+// word of the dictionary instead of using the index of deletes. It is much
+// slower, but it doesn't depend on the prefix length or on how deletes are
+// hashed, which makes it a reference to check Lookup against.
+func (model *SpellModel) LookupExhaustive(input string, opts ...LookupOption) (utils.SuggestionList, error) {
+	return model.Lookup(input, withLookupOptions(opts, exhaustive())...)
+}
+
+// exhaustive makes a lookup compare the input with every word
+func exhaustive() LookupOption {
+	return func(lp *lookupParams) error {
+		lp.exhaustive = true
+		return nil
+	}
+}
+
+// lookupExhaustive compares the input with every word of the dictionary of the
+// lookup params
+func (model *SpellModel) lookupExhaustive(input string, lookupParams *lookupParams) (utils.SuggestionList, error) {
+	dict := lookupParams.dictOpts.Name
+	editDistance := int(lookupParams.editDistance)
+	inputRunes := []rune(input)
+	results := utils.SuggestionList{}
+
+	for _, word := range model.library.Words(dict) {
+		// Keep the suggestions found so far if the lookup runs out of time
+		if stop, err := lookupParams.interrupted(); err != nil {
+			return nil, err
+		} else if stop {
+			break
+		}
+
+		entry, exists := model.library.Load(dict, word)
+		if !exists || !lookupParams.accepts(entry) {
+			continue
+		}
+
+		wordRunes := []rune(word)
+
+		// The distance allowed for this word
+		maxDist := editDistance
+		if lookupParams.minSimilarity > 0 {
+			maxDist = utils.Min(maxDist, lookupParams.similarDistance(len(inputRunes), len(wordRunes)))
+		}
+
+		// The input itself is an exact match, other words are measured as
+		// they are by the index of deletes
+		var dist int
+		var cost float64
+		if word != input {
+			var ok bool
+			if dist, cost, ok = lookupParams.measure(inputRunes, wordRunes, maxDist); !ok || dist > maxDist {
+				continue
+			}
+		}
+
+		result := model.newDictSuggestion(word, dist, lookupParams.dictOpts)
+		result.Cost = cost
+		results = append(results, result)
+	}
+
+	if lookupParams.phonetic {
+		results = model.mergePhonetic(input, results, lookupParams)
+	}
+
+	lookupParams.sortFunc(results)
+
+	return lookupParams.limitResults(results), nil
+}
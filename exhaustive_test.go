@@ -0,0 +1,179 @@
+package ta
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+func ExampleSpellModel_LookupExhaustive() {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "example"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 5, Word: "examples"})
+
+	suggestions, _ := s.LookupExhaustive("exampel", SuggestionLevel(ALL))
+	fmt.Println(suggestions)
+	// Output:
+	// [example, examples]
+}
+
+// randomWord returns a word of 1 to maxLen runes from a small alphabet, so
+// that words are close to each other
+func randomWord(r *rand.Rand, maxLen int) string {
+	alphabet := []rune("abcdé")
+	word := make([]rune, 1+r.Intn(maxLen))
+	for i := range word {
+		word[i] = alphabet[r.Intn(len(alphabet))]
+	}
+	return string(word)
+}
+
+// suggestionKeys returns the words and distances of suggestions, sorted
+func suggestionKeys(suggestions utils.SuggestionList) []string {
+	keys := make([]string, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		keys = append(keys, fmt.Sprintf("%s:%d", suggestion.Word, suggestion.Distance))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestLookup_exhaustive(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for m := 0; m < 50; m++ {
+		s := NewSpellModel()
+		s.MaxEditDistance = uint32(1 + r.Intn(3))
+		// Prefixes as short as a single rune, even shorter than the max edit
+		// distance, are truncated the most
+		s.PrefixLength = uint32(1 + r.Intn(8))
+
+		// Distinct words with distinct frequencies make the best suggestion
+		// unique
+		frequencies := r.Perm(200)
+		words := make(map[string]struct{})
+		for i := 0; i < 200; i++ {
+			word := randomWord(r, 10)
+			if utils.AddKey(words, word) {
+				_, _ = s.AddEntry(utils.Entry{Frequency: uint64(frequencies[i] + 1), Word: word})
+			}
+		}
+
+		// Removed words must not be suggested
+		for i := 0; i < 10; i++ {
+			_, _ = s.RemoveEntry(randomWord(r, 3))
+		}
+
+		for i := 0; i < 100; i++ {
+			input := randomWord(r, 12)
+			editDistance := EditDistance(uint32(r.Intn(int(s.MaxEditDistance) + 1)))
+
+			for _, level := range []suggestionLevel{BEST, CLOSEST, ALL} {
+				opts := []LookupOption{editDistance, SuggestionLevel(level)}
+
+				want, err := s.LookupExhaustive(input, opts...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := s.Lookup(input, opts...)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				wantKeys := suggestionKeys(want)
+				gotKeys := suggestionKeys(got)
+				if fmt.Sprint(gotKeys) != fmt.Sprint(wantKeys) {
+					t.Fatalf("Lookup(%q) at level %v with max edit distance %v and prefix length %v "+
+						"returned %v, want %v", input, level, s.MaxEditDistance, s.PrefixLength,
+						gotKeys, wantKeys)
+				}
+			}
+		}
+	}
+}
+
+func TestLookup_shortWords(t *testing.T) {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "b"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 5, Word: "cb"})
+
+	// Words that can be deleted entirely are found from any short input
+	suggestions, err := s.Lookup("c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[b]" {
+		t.Fatalf("Expected [b], got %v", suggestions)
+	}
+}
+
+func TestLookup_shortPrefix(t *testing.T) {
+	s := NewSpellModel()
+	s.PrefixLength = 1
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "bca"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 5, Word: "éa"})
+
+	// Neither word shares its prefix with the input, and éa is closer than
+	// its length suggests
+	suggestions, err := s.Lookup("a", SuggestionLevel(ALL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := suggestionKeys(suggestions); fmt.Sprint(keys) != "[bca:2 éa:1]" {
+		t.Fatalf("Expected [bca:2 éa:1], got %v", keys)
+	}
+}
+
+func TestLookup_removedWords(t *testing.T) {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "example"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 5, Word: "examples"})
+	_, _ = s.RemoveEntry("example")
+
+	// The deletes of a removed word are still indexed, but the word is no
+	// longer suggested, as the exhaustive lookup agrees
+	for _, lookup := range []func(string, ...LookupOption) (utils.SuggestionList, error){
+		s.Lookup, s.LookupExhaustive,
+	} {
+		suggestions, err := lookup("exampel", SuggestionLevel(ALL))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if suggestions.String() != "[examples]" {
+			t.Fatalf("Expected [examples], got %v", suggestions)
+		}
+	}
+}
+
+func TestLookup_zeroCost(t *testing.T) {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "colour"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 5, Word: "color"})
+
+	// A cost of 0 between different words, here ignoring the u of colour
+	cost := func(a, b []rune, maxCost float64) float64 {
+		strip := func(r []rune) string { return strings.Replace(string(r), "u", "", -1) }
+		d := float64(utils.DamerauLevenshteinRunes([]rune(strip(a)), []rune(strip(b)), int(maxCost)))
+		if d > maxCost {
+			return -1
+		}
+		return d
+	}
+
+	// Only the input itself is an exact match, whichever lookup is used
+	for _, lookup := range []func(string, ...LookupOption) (utils.SuggestionList, error){
+		s.Lookup, s.LookupExhaustive,
+	} {
+		suggestions, err := lookup("color", SuggestionLevel(ALL), WeightedDistanceFunc(cost))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if keys := suggestionKeys(suggestions); fmt.Sprint(keys) != "[color:0]" {
+			t.Fatalf("Expected [color:0], got %v", keys)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package ta
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+// ImportFrequencies adds the words of a frequency list, such as the "word
+// count" files of SymSpell, to a dictionary. Each row holds a word and its
+// count in the columns set with ImportColumns, and optionally other columns
+// that are stored in WordData, see ImportDataColumn. Blank lines and comment
+// lines are skipped, and so are malformed rows unless ImportStopOnError is set.
+// Merges with any dictionary data already loaded.
+//
+// Accepts zero or more ImportOption that can be used to configure how the
+// import occurs.
+func (model *SpellModel) ImportFrequencies(filePath string, opts ...ImportOption) (*ImportReport, error) {
+	importParams, err := model.newImportParams(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	report := &ImportReport{}
+
+	s := bufio.NewScanner(f)
+	line := 0
+
+	for s.Scan() {
+		line++
+
+		text := strings.TrimSpace(s.Text())
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\uFEFF")
+		}
+		if text == "" || (importParams.comment != "" && strings.HasPrefix(text, importParams.comment)) {
+			continue
+		}
+
+		entry, err := importParams.parseRow(text)
+		if err != nil {
+			if err := importParams.malformed(report, filePath, line, err); err != nil {
+				return report, err
+			}
+			continue
+		}
+		report.Read++
+
+		added, err := model.AddEntry(entry, importParams.dictionaryOptions...)
+		if err != nil {
+			return report, err
+		}
+		if added {
+			report.Added++
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return report, &ImportError{Path: filePath, Line: line, Err: err}
+	}
+
+	return report, nil
+}
+
+// parseRow parses a row of a frequency list into an entry
+func (ip *importParams) parseRow(text string) (utils.Entry, error) {
+	var columns []string
+	if ip.separator == "" {
+		columns = strings.Fields(text)
+	} else {
+		columns = strings.Split(text, ip.separator)
+	}
+
+	return ip.parseColumns(columns, ip.dataColumns, func(text string) interface{} {
+		return text
+	})
+}
+
+// parseColumns parses the columns of a row into an entry. The columns that
+// have a key are stored in WordData, converted with value.
+func (ip *importParams) parseColumns(columns []string, keys map[int]string, value func(string) interface{}) (utils.Entry, error) {
+	for i := range columns {
+		columns[i] = strings.TrimSpace(columns[i])
+	}
+
+	entry := utils.Entry{Frequency: 1}
+
+	if ip.termColumn >= len(columns) || columns[ip.termColumn] == "" {
+		return entry, fmt.Errorf("missing word in column %d", ip.termColumn)
+	}
+	entry.Word = columns[ip.termColumn]
+
+	if ip.countColumn >= 0 {
+		if ip.countColumn >= len(columns) {
+			return entry, fmt.Errorf("missing count in column %d", ip.countColumn)
+		}
+		count, err := strconv.ParseUint(columns[ip.countColumn], 10, 64)
+		if err != nil {
+			return entry, fmt.Errorf("invalid count %q in column %d", columns[ip.countColumn], ip.countColumn)
+		}
+		entry.Frequency = count
+	}
+
+	for column, key := range keys {
+		if column < len(columns) && columns[column] != "" {
+			if entry.WordData == nil {
+				entry.WordData = utils.WordData{}
+			}
+			entry.WordData[key] = value(columns[column])
+		}
+	}
+
+	return entry, nil
+}
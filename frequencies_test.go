@@ -0,0 +1,108 @@
+package ta
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+// writeTemp writes content to a temporary file and returns its path
+func writeTemp(t *testing.T, pattern, content string) string {
+	f, err := ioutil.TempFile("", pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(content)
+	_ = f.Close()
+	return f.Name()
+}
+
+func TestImportFrequencies(t *testing.T) {
+	path := writeTemp(t, "frequencies", "# word count\nthe 23135851162\n\nof\t13151942776\nand x\nand 12997637966\n")
+	defer os.Remove(path)
+
+	s := NewSpellModel()
+	report, err := s.ImportFrequencies(path, ImportDictionaryOpts(DictionaryName("en")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Read != 3 || report.Added != 3 {
+		t.Fatalf("Expected 3 read and 3 added, got %+v", report)
+	}
+	if len(report.Skipped) != 1 || report.Skipped[0].Line != 5 {
+		t.Fatalf("Expected line 5 to be skipped, got %v", report.Skipped)
+	}
+
+	entry, _ := s.GetEntry("of", DictionaryName("en"))
+	if entry == nil || entry.Frequency != 13151942776 {
+		t.Fatalf("Expected of with frequency 13151942776, got %+v", entry)
+	}
+	if s.cumulativeFreq != 23135851162+13151942776+12997637966 {
+		t.Fatalf("Unexpected cumulative frequency %d", s.cumulativeFreq)
+	}
+
+	_, err = s.ImportFrequencies(path, ImportStopOnError(true))
+	var importErr *ImportError
+	if !errors.As(err, &importErr) || importErr.Line != 5 {
+		t.Fatalf("Expected an import error at line 5, got %v", err)
+	}
+}
+
+func TestImportFrequencies_columns(t *testing.T) {
+	path := writeTemp(t, "frequencies", "1;colour;noun;45\n2;honour;noun;30\n3;run\n")
+	defer os.Remove(path)
+
+	s := NewSpellModel()
+	report, err := s.ImportFrequencies(path,
+		ImportSeparator(";"),
+		ImportColumns(1, -1),
+		ImportDataColumn(2, "pos"),
+		ImportDataColumn(3, "rank"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Read != 3 || len(report.Skipped) != 0 {
+		t.Fatalf("Expected 3 rows read, got %+v", report)
+	}
+
+	entry, _ := s.GetEntry("colour")
+	if entry.Frequency != 1 || entry.WordData["pos"] != "noun" || entry.WordData["rank"] != "45" {
+		t.Fatalf("Unexpected entry %+v", entry)
+	}
+	if entry, _ = s.GetEntry("run"); entry.WordData != nil {
+		t.Fatalf("Expected no word data for run, got %v", entry.WordData)
+	}
+
+	if _, err := s.ImportFrequencies(path, ImportColumns(1, 1)); err == nil {
+		t.Fatal("Expected an error for the same word and count column")
+	}
+}
+
+func TestCreateDictionary(t *testing.T) {
+	path := writeTemp(t, "words", "cat\ndog\n")
+	defer os.Remove(path)
+
+	s := NewSpellModel()
+	if _, err := s.CreateDictionary(path, DictionaryName("pets")); err != nil {
+		t.Fatal(err)
+	}
+
+	if entry, _ := s.GetEntry("cat", DictionaryName("pets")); entry == nil {
+		t.Fatal("Expected cat in the pets dictionary")
+	}
+	if entry, _ := s.GetEntry("cat"); entry != nil {
+		t.Fatal("Expected cat not to be in the default dictionary")
+	}
+
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "dog"})
+	if _, err := s.CreateDictionary(path, OverrideFrequency(true)); err != nil {
+		t.Fatal(err)
+	}
+	if entry, _ := s.GetEntry("dog"); entry.Frequency != 1 {
+		t.Fatalf("Expected the frequency of dog to be overridden, got %d", entry.Frequency)
+	}
+}
@@ -1,8 +1,5 @@
 module github.com/agusnavce/ta
 
-go 1.13
+go 1.17
 
-require (
-	github.com/mitchellh/mapstructure v1.2.2
-	github.com/tidwall/gjson v1.6.0
-)
+require golang.org/x/text v0.3.8
@@ -0,0 +1,600 @@
+package ta
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+// Hunspell flag types, see the FLAG directive
+const (
+	hunspellFlagChar = iota
+	hunspellFlagLong
+	hunspellFlagNum
+)
+
+// Directives of an affix file that only matter to how Hunspell suggests and
+// checks words, so they are ignored rather than reported
+var hunspellIgnored = map[string]struct{}{
+	"AUTHOR": {}, "BREAK": {}, "CHECKSHARPS": {}, "FORBIDWARN": {},
+	"FORCEUCASE": {}, "FULLSTRIP": {}, "HOME": {}, "ICONV": {}, "KEEPCASE": {},
+	"KEY": {}, "LANG": {}, "LEMMA_PRESENT": {}, "MAP": {}, "MAXCPDSUGS": {},
+	"MAXDIFF": {}, "MAXNGRAMSUGS": {}, "NAME": {}, "NOSPLITSUGS": {},
+	"OCONV": {}, "ONLYMAXDIFF": {}, "PHONE": {}, "REP": {}, "SUBSTANDARD": {},
+	"SUGSWITHDOTS": {}, "TRY": {}, "VERSION": {}, "WARN": {}, "WORDCHARS": {},
+	"AM": {},
+}
+
+type hunspellRule struct {
+	strip     string
+	add       string
+	flags     []string
+	condition *regexp.Regexp
+}
+
+type hunspellAffix struct {
+	prefix bool
+	cross  bool
+	rules  []hunspellRule
+	// The number of rules of the affix still to be read
+	remaining int
+}
+
+type hunspellAffixes struct {
+	flagType int
+	aliases  [][]string
+	affixes  map[string]*hunspellAffix
+	// The flag of words that are only valid with an affix
+	needAffix string
+	// The flags of words that must not be suggested
+	skipFlags map[string]struct{}
+}
+
+// ImportHunspell adds the words of a Hunspell dictionary, given the paths to
+// its .aff and .dic files, together with every form its prefix and suffix
+// rules allow, e.g. "cats" from "cat/S". Each form is added with a frequency
+// of 1, and the WordData "lemma" and "flags" of the word of the .dic file it
+// was expanded from.
+//
+// Words flagged as forbidden, only valid in compounds or not to be suggested
+// are left out. Rules and directives that can't be handled, such as
+// compounding, are skipped and listed in the report, and so are malformed
+// lines unless ImportStopOnError is set. Only UTF-8 dictionaries are
+// supported.
+//
+// Accepts zero or more ImportOption that can be used to configure how the
+// import occurs. Options about columns and separators don't apply.
+func (model *SpellModel) ImportHunspell(affPath, dicPath string, opts ...ImportOption) (*ImportReport, error) {
+	importParams, err := model.newImportParams(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{}
+
+	affixes, err := readHunspellAffixes(affPath, importParams, report)
+	if err != nil {
+		return report, err
+	}
+
+	f, err := os.Open(dicPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	line := 0
+
+	for s.Scan() {
+		line++
+
+		text := strings.TrimSpace(s.Text())
+		if line == 1 {
+			// The first line holds the number of words
+			text = strings.TrimPrefix(text, "\uFEFF")
+			if _, err := strconv.Atoi(text); err == nil {
+				continue
+			}
+		}
+		if text == "" {
+			continue
+		}
+
+		word, flags, err := affixes.parseEntry(text)
+		if err != nil {
+			if err := importParams.malformed(report, dicPath, line, err); err != nil {
+				return report, err
+			}
+			continue
+		}
+		report.Read++
+
+		if affixes.skipped(flags) {
+			continue
+		}
+
+		for _, form := range affixes.expand(word, flags) {
+			wordData := utils.WordData{"lemma": word}
+			if len(flags) > 0 {
+				wordData["flags"] = affixes.formatFlags(flags)
+			}
+
+			added, err := model.AddEntry(utils.Entry{
+				Frequency: 1,
+				Word:      form,
+				WordData:  wordData,
+			}, importParams.dictionaryOptions...)
+			if err != nil {
+				return report, err
+			}
+			if added {
+				report.Added++
+			}
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return report, &ImportError{Path: dicPath, Line: line, Err: err}
+	}
+
+	return report, nil
+}
+
+// readHunspellAffixes reads the affix file at path
+func readHunspellAffixes(path string, ip *importParams, report *ImportReport) (*hunspellAffixes, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := &hunspellAffixes{
+		affixes:   make(map[string]*hunspellAffix),
+		skipFlags: make(map[string]struct{}),
+	}
+
+	// Tables of unsupported directives are only reported once
+	reported := make(map[string]struct{})
+	aliasCount := false
+
+	s := bufio.NewScanner(f)
+	line := 0
+
+	for s.Scan() {
+		line++
+
+		text := s.Text()
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\uFEFF")
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		directive := fields[0]
+		if _, ignored := hunspellIgnored[directive]; ignored {
+			continue
+		}
+
+		if len(fields) < 2 {
+			if err := ip.malformed(report, path, line, fmt.Errorf("%s without a value", directive)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		switch directive {
+		case "SET":
+			if !strings.EqualFold(fields[1], "UTF-8") {
+				return nil, &ImportError{
+					Path: path,
+					Line: line,
+					Err:  fmt.Errorf("unsupported encoding %s, only UTF-8 is supported", fields[1]),
+				}
+			}
+		case "FLAG":
+			switch fields[1] {
+			case "long":
+				h.flagType = hunspellFlagLong
+			case "num":
+				h.flagType = hunspellFlagNum
+			case "UTF-8":
+				h.flagType = hunspellFlagChar
+			default:
+				report.skip(path, line, fmt.Errorf("unsupported flag type %s", fields[1]))
+			}
+		case "AF":
+			// The first line of the table holds the number of aliases
+			if !aliasCount {
+				aliasCount = true
+				continue
+			}
+			flags, err := h.parseRawFlags(fields[1])
+			if err != nil {
+				if err := ip.malformed(report, path, line, err); err != nil {
+					return nil, err
+				}
+				flags = nil
+			}
+			// Keep the numbering of the aliases even if one is malformed
+			h.aliases = append(h.aliases, flags)
+		case "NEEDAFFIX", "PSEUDOROOT":
+			h.needAffix = fields[1]
+		case "FORBIDDENWORD", "ONLYINCOMPOUND", "NOSUGGEST":
+			h.skipFlags[fields[1]] = struct{}{}
+		case "PFX", "SFX":
+			if err := h.parseAffix(fields); err != nil {
+				if err := ip.malformed(report, path, line, err); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			if utils.AddKey(reported, directive) {
+				report.skip(path, line, fmt.Errorf("unsupported directive %s", directive))
+			}
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, &ImportError{Path: path, Line: line, Err: err}
+	}
+
+	return h, nil
+}
+
+// parseAffix parses the header or a rule of a prefix or suffix
+func (h *hunspellAffixes) parseAffix(fields []string) error {
+	prefix := fields[0] == "PFX"
+	flag := fields[1]
+
+	affix, exists := h.affixes[flag]
+	if !exists || affix.remaining == 0 {
+		if len(fields) < 4 {
+			return fmt.Errorf("%s %s header needs a cross product and a number of rules", fields[0], flag)
+		}
+		count, err := strconv.Atoi(fields[3])
+		if err != nil {
+			return fmt.Errorf("%s %s header: %w", fields[0], flag, err)
+		}
+		if !exists {
+			affix = &hunspellAffix{prefix: prefix}
+			h.affixes[flag] = affix
+		}
+		affix.cross = fields[2] == "Y"
+		affix.remaining = count
+		return nil
+	}
+
+	affix.remaining--
+
+	if affix.prefix != prefix {
+		return fmt.Errorf("%s %s rule of a flag defined as the other kind of affix", fields[0], flag)
+	}
+	if len(fields) < 4 {
+		return fmt.Errorf("%s %s rule needs a strip and an affix", fields[0], flag)
+	}
+
+	rule := hunspellRule{strip: fields[2]}
+	if rule.strip == "0" {
+		rule.strip = ""
+	}
+
+	add := fields[3]
+	if i := strings.Index(add, "/"); i >= 0 {
+		flags, err := h.parseFlags(add[i+1:])
+		if err != nil {
+			return err
+		}
+		add, rule.flags = add[:i], flags
+	}
+	if add != "0" {
+		rule.add = add
+	}
+
+	condition := "."
+	if len(fields) > 4 {
+		condition = fields[4]
+	}
+
+	var err error
+	if rule.condition, err = hunspellCondition(condition, prefix); err != nil {
+		return err
+	}
+
+	affix.rules = append(affix.rules, rule)
+	return nil
+}
+
+// hunspellCondition compiles the condition of an affix rule, which matches the
+// start of a word for prefixes and the end of a word for suffixes
+func hunspellCondition(condition string, prefix bool) (*regexp.Regexp, error) {
+	var expr strings.Builder
+	runes := []rune(condition)
+
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '.':
+			expr.WriteString(".")
+		case '[':
+			end := i + 1
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unclosed [ in condition %s", condition)
+			}
+
+			class := runes[i+1 : end]
+			expr.WriteString("[")
+			if len(class) > 0 && class[0] == '^' {
+				expr.WriteString("^")
+				class = class[1:]
+			}
+			if len(class) == 0 {
+				return nil, fmt.Errorf("empty class in condition %s", condition)
+			}
+			for _, r := range class {
+				fmt.Fprintf(&expr, `\x{%x}`, r)
+			}
+			expr.WriteString("]")
+			i = end
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(runes[i])))
+		}
+	}
+
+	if prefix {
+		return regexp.Compile("^(?:" + expr.String() + ")")
+	}
+	return regexp.Compile("(?:" + expr.String() + ")$")
+}
+
+// parseEntry parses a line of a .dic file into its word and flags
+func (h *hunspellAffixes) parseEntry(text string) (string, []string, error) {
+	// Morphological fields may follow the word and its flags
+	if i := strings.IndexAny(text, " \t"); i >= 0 {
+		text = text[:i]
+	}
+
+	// A slash that is part of the word is escaped with a backslash
+	var word strings.Builder
+	rawFlags := ""
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' && i+1 < len(text) && text[i+1] == '/' {
+			word.WriteByte('/')
+			i++
+			continue
+		}
+		if text[i] == '/' {
+			rawFlags = text[i+1:]
+			break
+		}
+		word.WriteByte(text[i])
+	}
+
+	if word.Len() == 0 {
+		return "", nil, errors.New("missing word")
+	}
+
+	flags, err := h.parseFlags(rawFlags)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return word.String(), flags, nil
+}
+
+// parseFlags parses flags, which may be the number of an alias
+func (h *hunspellAffixes) parseFlags(s string) ([]string, error) {
+	if s != "" && len(h.aliases) > 0 {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("flag alias %s is not a number", s)
+		}
+		if n < 1 || n > len(h.aliases) {
+			return nil, fmt.Errorf("unknown flag alias %d", n)
+		}
+		return h.aliases[n-1], nil
+	}
+
+	return h.parseRawFlags(s)
+}
+
+// parseRawFlags parses flags written out according to the flag type
+func (h *hunspellAffixes) parseRawFlags(s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var flags []string
+
+	switch h.flagType {
+	case hunspellFlagLong:
+		runes := []rune(s)
+		if len(runes)%2 != 0 {
+			return nil, fmt.Errorf("long flags %s have an odd number of characters", s)
+		}
+		for i := 0; i < len(runes); i += 2 {
+			flags = append(flags, string(runes[i:i+2]))
+		}
+	case hunspellFlagNum:
+		for _, flag := range strings.Split(s, ",") {
+			if _, err := strconv.Atoi(flag); err != nil {
+				return nil, fmt.Errorf("numeric flag %s is not a number", flag)
+			}
+			flags = append(flags, flag)
+		}
+	default:
+		for _, r := range s {
+			flags = append(flags, string(r))
+		}
+	}
+
+	return flags, nil
+}
+
+// formatFlags writes flags out according to the flag type
+func (h *hunspellAffixes) formatFlags(flags []string) string {
+	if h.flagType == hunspellFlagNum {
+		return strings.Join(flags, ",")
+	}
+	return strings.Join(flags, "")
+}
+
+// skipped reports whether a word with flags must be left out
+func (h *hunspellAffixes) skipped(flags []string) bool {
+	for _, flag := range flags {
+		if _, exists := h.skipFlags[flag]; exists {
+			return true
+		}
+	}
+	return false
+}
+
+// hasFlag reports whether flags hold flag
+func hasFlag(flags []string, flag string) bool {
+	for _, f := range flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// expand returns the word and the forms its flags allow
+func (h *hunspellAffixes) expand(word string, flags []string) []string {
+	found := make(map[string]struct{})
+	var forms []string
+
+	add := func(form string, flags []string) {
+		if h.needAffix != "" && hasFlag(flags, h.needAffix) {
+			return
+		}
+		if utils.AddKey(found, form) {
+			forms = append(forms, form)
+		}
+	}
+
+	add(word, flags)
+
+	// Suffixes come first, keeping the forms that prefixes can be combined
+	// with
+	var crossed []string
+	for _, flag := range flags {
+		affix, exists := h.affixes[flag]
+		if !exists || affix.prefix {
+			continue
+		}
+
+		for _, rule := range affix.rules {
+			form, ok := rule.apply(word, false)
+			if !ok {
+				continue
+			}
+			add(form, rule.flags)
+
+			// The flags of a rule add a second suffix
+			for _, next := range rule.flags {
+				if second, exists := h.affixes[next]; exists && !second.prefix {
+					for _, secondRule := range second.rules {
+						if secondForm, ok := secondRule.apply(form, false); ok {
+							add(secondForm, secondRule.flags)
+						}
+					}
+				}
+			}
+
+			if affix.cross {
+				crossed = append(crossed, form)
+			}
+		}
+	}
+
+	for _, flag := range flags {
+		affix, exists := h.affixes[flag]
+		if !exists || !affix.prefix {
+			continue
+		}
+
+		for _, rule := range affix.rules {
+			// The condition of a prefix is tested against the word, even when
+			// a suffix is added as well
+			form, ok := rule.apply(word, true)
+			if !ok {
+				continue
+			}
+			add(form, rule.flags)
+
+			if affix.cross {
+				for _, suffixed := range crossed {
+					if crossedForm, ok := rule.attach(suffixed, true); ok {
+						add(crossedForm, rule.flags)
+					}
+				}
+			}
+
+			// The flags of a rule add a suffix, or a second prefix
+			for _, next := range rule.flags {
+				second, exists := h.affixes[next]
+				if !exists {
+					continue
+				}
+
+				for _, secondRule := range second.rules {
+					if second.prefix {
+						if secondForm, ok := secondRule.apply(form, true); ok {
+							add(secondForm, secondRule.flags)
+						}
+						continue
+					}
+
+					if suffixed, ok := secondRule.apply(word, false); ok {
+						if secondForm, ok := rule.attach(suffixed, true); ok {
+							add(secondForm, secondRule.flags)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return forms
+}
+
+// apply returns the word with the affix of the rule, if its condition matches
+func (rule hunspellRule) apply(word string, prefix bool) (string, bool) {
+	if !rule.matches(word) {
+		return "", false
+	}
+	return rule.attach(word, prefix)
+}
+
+// matches reports whether the condition of the rule matches the word
+func (rule hunspellRule) matches(word string) bool {
+	return len(word) > len(rule.strip) && rule.condition.MatchString(word)
+}
+
+// attach returns the word with the affix of the rule in place of its strip,
+// whether or not its condition matches
+func (rule hunspellRule) attach(word string, prefix bool) (string, bool) {
+	if prefix {
+		if !strings.HasPrefix(word, rule.strip) {
+			return "", false
+		}
+		return rule.add + word[len(rule.strip):], true
+	}
+
+	if !strings.HasSuffix(word, rule.strip) {
+		return "", false
+	}
+	return word[:len(word)-len(rule.strip)] + rule.add, true
+}
@@ -0,0 +1,234 @@
+package ta
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+// writeHunspell writes an affix and a dictionary file to a temporary
+// directory and returns their paths
+func writeHunspell(t *testing.T, aff, dic string) (string, string, func()) {
+	dir, err := ioutil.TempDir("", "hunspell")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	affPath := filepath.Join(dir, "test.aff")
+	dicPath := filepath.Join(dir, "test.dic")
+	_ = ioutil.WriteFile(affPath, []byte(aff), 0644)
+	_ = ioutil.WriteFile(dicPath, []byte(dic), 0644)
+
+	return affPath, dicPath, func() { os.RemoveAll(dir) }
+}
+
+const testAff = `SET UTF-8
+TRY esianrtolcdugmphbyfvkwz
+
+PFX U Y 1
+PFX U 0 un .
+
+SFX S Y 3
+SFX S y ies [^aeiou]y
+SFX S 0 s [aeiou]y
+SFX S 0 s [^y]
+
+SFX B N 1
+SFX B 0 able/S .
+
+NEEDAFFIX N
+FORBIDDENWORD F
+
+COMPOUNDFLAG X
+COMPOUNDMIN 3
+
+SFX Q Y 1
+SFX Q 0
+`
+
+const testDic = `7
+happy/U
+fly/S
+lock/US
+drink/B
+kind/UN
+wrong/F
+/S
+`
+
+func TestImportHunspell(t *testing.T) {
+	affPath, dicPath, cleanup := writeHunspell(t, testAff, testDic)
+	defer cleanup()
+
+	s := NewSpellModel()
+	report, err := s.ImportHunspell(affPath, dicPath, ImportDictionaryOpts(DictionaryName("en")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	words := s.library.Words("en")
+	sort.Strings(words)
+	expected := "[drink drinkable drinkables flies fly happy lock locks unhappy unkind unlock unlocks]"
+	if got := "[" + strings.Join(words, " ") + "]"; got != expected {
+		t.Fatalf("Expected %s, got %s", expected, got)
+	}
+
+	if report.Read != 6 || report.Added != 12 {
+		t.Fatalf("Expected 6 read and 12 added, got %+v", report)
+	}
+
+	// The unsupported compounding and the malformed rule and word are
+	// reported with their lines
+	var lines []int
+	for _, skipped := range report.Skipped {
+		lines = append(lines, skipped.Line)
+	}
+	if len(lines) != 4 || lines[0] != 18 || lines[1] != 19 || lines[2] != 22 || lines[3] != 8 {
+		t.Fatalf("Expected skipped lines [18 19 22 8], got %v: %v", lines, report.Skipped)
+	}
+	if report.Skipped[3].Path != dicPath {
+		t.Fatalf("Expected the malformed word to be in %s, got %s", dicPath, report.Skipped[3].Path)
+	}
+
+	entry, _ := s.GetEntry("unlocks", DictionaryName("en"))
+	if entry == nil || entry.WordData["lemma"] != "lock" || entry.WordData["flags"] != "US" {
+		t.Fatalf("Expected lemma lock and flags US, got %+v", entry)
+	}
+}
+
+func TestImportHunspell_stopOnError(t *testing.T) {
+	affPath, dicPath, cleanup := writeHunspell(t, testAff, testDic)
+	defer cleanup()
+
+	// Unsupported directives are still skipped, but the malformed rule stops
+	// the import
+	s := NewSpellModel()
+	report, err := s.ImportHunspell(affPath, dicPath, ImportStopOnError(true))
+	var importErr *ImportError
+	if !errors.As(err, &importErr) || importErr.Path != affPath || importErr.Line != 22 {
+		t.Fatalf("Expected an import error at %s:22, got %v", affPath, err)
+	}
+	if len(report.Skipped) != 2 {
+		t.Fatalf("Expected the 2 unsupported directives to be skipped, got %v", report.Skipped)
+	}
+
+	// Dictionary options are passed on as they are
+	affPath, dicPath, cleanup = writeHunspell(t, "SFX S Y 1\nSFX S 0 s .\n", "1\ncat/S\n")
+	defer cleanup()
+
+	s = NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Frequency: 7, Word: "cats"})
+	if _, err := s.ImportHunspell(affPath, dicPath,
+		ImportDictionaryOpts(OverrideFrequency(true))); err != nil {
+		t.Fatal(err)
+	}
+	if entry, _ := s.GetEntry("cats"); entry == nil || entry.Frequency != 1 {
+		t.Fatalf("Expected the frequency of cats to be overridden, got %+v", entry)
+	}
+}
+
+func TestImportHunspell_crossProduct(t *testing.T) {
+	aff := `PFX A Y 1
+PFX A 0 re ...
+
+PFX P Y 1
+PFX P 0 un/S .
+
+SFX S Y 1
+SFX S 0 s .
+`
+	// The condition of A is tested against go rather than gos, and P adds S
+	// only together with un
+	affPath, dicPath, cleanup := writeHunspell(t, aff, "3\ngo/AS\nmake/AS\ndo/P\n")
+	defer cleanup()
+
+	s := NewSpellModel()
+	if _, err := s.ImportHunspell(affPath, dicPath); err != nil {
+		t.Fatal(err)
+	}
+
+	words := s.library.Words(defaultDict)
+	sort.Strings(words)
+	expected := "do go gos make makes remake remakes undo undos"
+	if got := strings.Join(words, " "); got != expected {
+		t.Fatalf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestImportHunspell_prefixStrip(t *testing.T) {
+	aff := `PFX A Y 1
+PFX A un re .
+`
+	// Only words that begin with the strip of a prefix take it
+	affPath, dicPath, cleanup := writeHunspell(t, aff, "2\ncat/A\nundo/A\n")
+	defer cleanup()
+
+	s := NewSpellModel()
+	report, err := s.ImportHunspell(affPath, dicPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Added != 3 {
+		t.Fatalf("Expected 3 added, got %+v", report)
+	}
+
+	words := s.library.Words(defaultDict)
+	sort.Strings(words)
+	expected := "cat redo undo"
+	if got := strings.Join(words, " "); got != expected {
+		t.Fatalf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestImportHunspell_flags(t *testing.T) {
+	aff := `FLAG long
+AF 2
+AF Aa
+AF AaBb
+SFX Aa Y 1
+SFX Aa 0 s .
+PFX Bb Y 1
+PFX Bb 0 re .
+`
+	affPath, dicPath, cleanup := writeHunspell(t, aff, "2\ncat/1\nmake/2\n")
+	defer cleanup()
+
+	s := NewSpellModel()
+	if _, err := s.ImportHunspell(affPath, dicPath); err != nil {
+		t.Fatal(err)
+	}
+
+	words := s.library.Words(defaultDict)
+	sort.Strings(words)
+	if got := strings.Join(words, " "); got != "cat cats make makes remake remakes" {
+		t.Fatalf("Expected cat cats make makes remake remakes, got %s", got)
+	}
+
+	entry, _ := s.GetEntry("remakes")
+	if entry.WordData["flags"] != "AaBb" {
+		t.Fatalf("Expected flags AaBb, got %v", entry.WordData["flags"])
+	}
+}
+
+func TestImportHunspell_errors(t *testing.T) {
+	affPath, dicPath, cleanup := writeHunspell(t, "SET ISO8859-1\n", "1\ncat\n")
+	defer cleanup()
+
+	s := NewSpellModel()
+	_, err := s.ImportHunspell(affPath, dicPath)
+
+	var importErr *ImportError
+	if !errors.As(err, &importErr) || importErr.Path != affPath || importErr.Line != 1 {
+		t.Fatalf("Expected an import error at %s:1, got %v", affPath, err)
+	}
+
+	if _, err := s.ImportHunspell(affPath+".missing", dicPath); err == nil {
+		t.Fatal("Expected an error for a missing file")
+	}
+}
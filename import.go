@@ -0,0 +1,184 @@
+package ta
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+// ImportError is a problem with an imported file, at a line of it if Line is
+// greater than 0. Path is empty when the import reads from an io.Reader.
+type ImportError struct {
+	Path string
+	Line int
+	Err  error
+}
+
+// Error returns the path and line of the problem followed by the problem
+func (e *ImportError) Error() string {
+	switch {
+	case e.Path == "" && e.Line > 0:
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	case e.Path == "":
+		return e.Err.Error()
+	case e.Line > 0:
+		return fmt.Sprintf("%s:%d: %v", e.Path, e.Line, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying problem
+func (e *ImportError) Unwrap() error {
+	return e.Err
+}
+
+// ImportReport summarizes an import
+type ImportReport struct {
+	// The number of entries read, e.g. the words of a Hunspell dictionary
+	// before their forms are expanded, or the distinct words of a corpus
+	Read int
+	// The number of words added to the dictionary, not counting words that
+	// were already in it
+	Added int
+	// The problems that were skipped rather than stopping the import, such as
+	// malformed lines or unsupported rules
+	Skipped []*ImportError
+}
+
+// skip records a problem that doesn't stop the import
+func (r *ImportReport) skip(path string, line int, err error) {
+	r.Skipped = append(r.Skipped, &ImportError{Path: path, Line: line, Err: err})
+}
+
+type importParams struct {
+	dictionaryOptions []utils.DictionaryOption
+	separator         string
+	termColumn        int
+	countColumn       int
+	dataColumns       map[int]string
+	header            map[string]string
+	comment           string
+	stopOnError       bool
+}
+
+func (model *SpellModel) defaultImportParams() *importParams {
+	return &importParams{
+		termColumn:  0,
+		countColumn: 1,
+		dataColumns: make(map[int]string),
+		comment:     "#",
+	}
+}
+
+// ImportOption is a function that controls how a dictionary is imported. An
+// error will be returned if the ImportOption is invalid.
+type ImportOption func(*importParams) error
+
+// ImportDictionaryOpts accepts multiple DictionaryOption and controls what
+// dictionary the words are added to and how
+func ImportDictionaryOpts(opts ...utils.DictionaryOption) ImportOption {
+	return func(ip *importParams) error {
+		ip.dictionaryOptions = append(ip.dictionaryOptions, opts...)
+		return nil
+	}
+}
+
+// ImportSeparator sets the separator between the columns of a row, e.g. "\t".
+// By default columns of a frequency list are separated by any amount of
+// whitespace, and columns of a CSV file by a comma.
+func ImportSeparator(separator string) ImportOption {
+	return func(ip *importParams) error {
+		ip.separator = separator
+		return nil
+	}
+}
+
+// ImportColumns sets which column, counting from 0, holds the word and which
+// holds its count. By default the word is in the first column and its count in
+// the second. A count column of -1 gives every word a count of 1.
+func ImportColumns(term, count int) ImportOption {
+	return func(ip *importParams) error {
+		if term < 0 || count < -1 {
+			return errors.New("columns must not be negative")
+		}
+		if term == count {
+			return errors.New("word and count must be in different columns")
+		}
+		ip.termColumn = term
+		ip.countColumn = count
+		return nil
+	}
+}
+
+// ImportDataColumn stores the value of a column, counting from 0, in the
+// WordData of each word under key. Rows without the column leave the key out.
+func ImportDataColumn(column int, key string) ImportOption {
+	return func(ip *importParams) error {
+		if column < 0 {
+			return errors.New("columns must not be negative")
+		}
+		if key == "" {
+			return errors.New("word data key must not be empty")
+		}
+		ip.dataColumns[column] = key
+		return nil
+	}
+}
+
+// ImportHeader renames the columns of a CSV file, or the WordData keys of a
+// JSON Lines file, e.g. from "Part of speech" to "pos". Columns and keys
+// renamed to "" are left out, and the others keep their name.
+func ImportHeader(mapping map[string]string) ImportOption {
+	return func(ip *importParams) error {
+		ip.header = mapping
+		return nil
+	}
+}
+
+// ImportComment sets the prefix of the lines to skip, which is "#" by
+// default. An empty prefix skips no lines.
+func ImportComment(prefix string) ImportOption {
+	return func(ip *importParams) error {
+		ip.comment = prefix
+		return nil
+	}
+}
+
+// ImportStopOnError defines whether an import stops with an ImportError at the
+// first malformed row, rather than skip it and list it in the report
+func ImportStopOnError(stop bool) ImportOption {
+	return func(ip *importParams) error {
+		ip.stopOnError = stop
+		return nil
+	}
+}
+
+func (model *SpellModel) newImportParams(opts []ImportOption) (*importParams, error) {
+	importParams := model.defaultImportParams()
+
+	for _, opt := range opts {
+		if err := opt(importParams); err != nil {
+			return nil, err
+		}
+	}
+
+	// Report invalid dictionary options once rather than for every word
+	dictOpts := model.defaultDictOptions()
+	for _, opt := range importParams.dictionaryOptions {
+		if err := opt(dictOpts); err != nil {
+			return nil, err
+		}
+	}
+
+	return importParams, nil
+}
+
+// malformed skips a malformed row, or stops the import with it
+func (ip *importParams) malformed(report *ImportReport, path string, line int, err error) error {
+	if ip.stopOnError {
+		return &ImportError{Path: path, Line: line, Err: err}
+	}
+	report.skip(path, line, err)
+	return nil
+}
@@ -0,0 +1,193 @@
+package ta
+
+import (
+	"errors"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+type matchParams struct {
+	dictOpts *utils.DictOptions
+	limit    int
+	offset   int
+	regexp   bool
+}
+
+func (model *SpellModel) defaultMatchParams() *matchParams {
+	return &matchParams{
+		dictOpts: model.defaultDictOptions(),
+	}
+}
+
+// MatchOption is a function that controls how a Match is performed. An error
+// will be returned if the MatchOption is invalid.
+type MatchOption func(*matchParams) error
+
+// MatchDictionaryOpts accepts multiple DictionaryOption and controls what
+// dictionary should be searched
+func MatchDictionaryOpts(opts ...utils.DictionaryOption) MatchOption {
+	return func(mp *matchParams) error {
+		for _, opt := range opts {
+			if err := opt(mp.dictOpts); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// MatchRegexp defines whether the pattern is an RE2 regular expression rather
+// than a wildcard pattern. Unlike wildcard patterns, regular expressions match
+// anywhere in a word unless anchored with ^ and $.
+func MatchRegexp(enabled bool) MatchOption {
+	return func(mp *matchParams) error {
+		mp.regexp = enabled
+		return nil
+	}
+}
+
+// MatchPage skips the first offset matches and returns at most limit of the
+// rest. A limit of 0 returns every match after the offset.
+func MatchPage(offset, limit int) MatchOption {
+	return func(mp *matchParams) error {
+		if offset < 0 || limit < 0 {
+			return errors.New("offset and limit must not be negative")
+		}
+		mp.offset = offset
+		mp.limit = limit
+		return nil
+	}
+}
+
+// MatchResult holds a page of the words that match a pattern
+type MatchResult struct {
+	// The number of words that match, across all pages
+	Total       int
+	Suggestions utils.SuggestionList
+}
+
+// Match returns the words of the dictionary that match a pattern, sorted by
+// frequency. By default the pattern must match the whole word, where ? matches
+// any character and * any number of characters, e.g. "c?t" or "inter*". Words
+// are matched as they are indexed, i.e. normalized if the model has a
+// normalizer.
+//
+// Accepts zero or more MatchOption that can be used to configure how matching
+// occurs.
+func (model *SpellModel) Match(pattern string, opts ...MatchOption) (*MatchResult, error) {
+	matchParams := model.defaultMatchParams()
+
+	for _, opt := range opts {
+		if err := opt(matchParams); err != nil {
+			return nil, err
+		}
+	}
+
+	dict := matchParams.dictOpts.Name
+
+	var re *regexp.Regexp
+	var prefix string
+	var err error
+
+	if matchParams.regexp {
+		if re, err = regexp.Compile(pattern); err != nil {
+			return nil, err
+		}
+		prefix = regexpPrefix(pattern)
+	} else {
+		pattern = model.normalize(pattern)
+		re, prefix = wildcardRegexp(pattern)
+	}
+
+	// Only words that start with the literal prefix can match, which the
+	// prefix index finds without going through the whole dictionary
+	var words []string
+	if prefix != "" {
+		for word := range model.prefixes.Match(dict, prefix, 0) {
+			words = append(words, word)
+		}
+	} else {
+		words = model.library.Words(dict)
+	}
+
+	results := utils.SuggestionList{}
+	for _, word := range words {
+		if re.MatchString(word) {
+			results = append(results, model.newDictSuggestion(word, 0, matchParams.dictOpts))
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Frequency != results[j].Frequency {
+			return results[i].Frequency > results[j].Frequency
+		}
+		return results[i].Word < results[j].Word
+	})
+
+	result := &MatchResult{Total: len(results)}
+
+	start := utils.Min(matchParams.offset, len(results))
+	end := len(results)
+	if matchParams.limit > 0 {
+		end = utils.Min(start+matchParams.limit, end)
+	}
+	result.Suggestions = results[start:end]
+
+	return result, nil
+}
+
+// wildcardRegexp compiles a wildcard pattern into a regular expression that
+// matches whole words, and returns the literal text before the first wildcard
+func wildcardRegexp(pattern string) (*regexp.Regexp, string) {
+	var expr strings.Builder
+	prefix := ""
+	literal := true
+
+	expr.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '?':
+			expr.WriteString(".")
+			literal = false
+		case '*':
+			expr.WriteString(".*")
+			literal = false
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+			if literal {
+				prefix += string(r)
+			}
+		}
+	}
+	expr.WriteString("$")
+
+	return regexp.MustCompile(expr.String()), prefix
+}
+
+// regexpPrefix returns the literal text every match of a regular expression
+// anchored at the start of the text begins with, or nothing if there is none
+func regexpPrefix(pattern string) string {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return ""
+	}
+	re = re.Simplify()
+
+	if re.Op != syntax.OpConcat || len(re.Sub) == 0 || re.Sub[0].Op != syntax.OpBeginText {
+		return ""
+	}
+
+	var prefix []rune
+	for _, sub := range re.Sub[1:] {
+		if sub.Op != syntax.OpLiteral || sub.Flags&syntax.FoldCase != 0 {
+			break
+		}
+		prefix = append(prefix, sub.Rune...)
+	}
+
+	return string(prefix)
+}
@@ -0,0 +1,94 @@
+package ta
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+func newWithMatches() *SpellModel {
+	s := NewSpellModel()
+	for i, word := range []string{"cat", "cot", "cut", "coat", "cart", "scat", "catalog", "c.t"} {
+		_, _ = s.AddEntry(utils.Entry{Frequency: uint64(10 * (i + 1)), Word: word})
+	}
+	_, _ = s.AddEntry(utils.Entry{Frequency: 1, Word: "cab"}, DictionaryName("other"))
+	return s
+}
+
+func ExampleSpellModel_Match() {
+	s := newWithMatches()
+
+	result, _ := s.Match("c?t")
+	fmt.Println(result.Total, result.Suggestions)
+
+	result, _ = s.Match("^ca(t|rt)$", MatchRegexp(true))
+	fmt.Println(result.Total, result.Suggestions)
+	// Output:
+	// 4 [c.t, cut, cot, cat]
+	// 2 [cart, cat]
+}
+
+func TestMatch(t *testing.T) {
+	s := newWithMatches()
+
+	tests := []struct {
+		pattern  string
+		opts     []MatchOption
+		total    int
+		expected string
+	}{
+		{"cat", nil, 1, "[cat]"},
+		{"c*t", nil, 6, "[c.t, cart, coat, cut, cot, cat]"},
+		{"*cat*", nil, 3, "[catalog, scat, cat]"},
+		{"c.t", nil, 1, "[c.t]"},
+		{"*", []MatchOption{MatchPage(2, 3)}, 8, "[scat, cart, coat]"},
+		{"*", []MatchOption{MatchPage(7, 3)}, 8, "[cat]"},
+		{"*", []MatchOption{MatchPage(20, 3)}, 8, "[]"},
+		{"ca?", []MatchOption{MatchDictionaryOpts(DictionaryName("other"))}, 1, "[cab]"},
+		{"at", []MatchOption{MatchRegexp(true)}, 4, "[catalog, scat, coat, cat]"},
+		{"^c[aeiou]+t$", []MatchOption{MatchRegexp(true)}, 4, "[coat, cut, cot, cat]"},
+		{"^(?i)CAT", []MatchOption{MatchRegexp(true)}, 2, "[catalog, cat]"},
+	}
+
+	for i, d := range tests {
+		result, err := s.Match(d.pattern, d.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Total != d.total || result.Suggestions.String() != d.expected {
+			t.Errorf("Test[%d]: expected %d %s, got %d %v",
+				i, d.total, d.expected, result.Total, result.Suggestions)
+		}
+	}
+
+	if _, err := s.Match("(", MatchRegexp(true)); err == nil {
+		t.Fatal("Expected an error for an invalid regular expression")
+	}
+	if _, err := s.Match("*", MatchPage(-1, 0)); err == nil {
+		t.Fatal("Expected an error for a negative offset")
+	}
+}
+
+func TestRegexpPrefix(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"^abc", "abc"},
+		{"^abc.*", "abc"},
+		{"^ab+c", "a"},
+		{"^ab(c|d)", "ab"},
+		{`^a\.b`, "a.b"},
+		{"abc", ""},
+		{"^(?i)abc", ""},
+		{"^abc|^abd", ""},
+		{"(?m)^abc", ""},
+	}
+
+	for i, d := range tests {
+		if got := regexpPrefix(d.pattern); got != d.want {
+			t.Errorf("Test[%d]: regexpPrefix(%q) returned %q, want %q", i, d.pattern, got, d.want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package ta
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+// AddMisspelling trains the error model with a misspelling and its correction
+func (model *SpellModel) AddMisspelling(misspelling, correction string) {
+	model.errorModel.Add(model.normalize(misspelling), model.normalize(correction))
+}
+
+// TrainErrorModel trains the error model from a file where each line holds a
+// misspelling and its correction separated by whitespace, e.g. "teh the".
+// Blank lines are ignored. Adds to any training already done.
+func (model *SpellModel) TrainErrorModel(filePath string) (bool, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	line := 0
+
+	for s.Scan() {
+		line++
+
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return false, fmt.Errorf("%s:%d: expected a misspelling and its correction", filePath, line)
+		}
+
+		model.AddMisspelling(fields[0], fields[1])
+	}
+
+	if err := s.Err(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// NoisyChannel defines whether candidates should be ranked by the noisy
+// channel model, the probability of the word times the probability of typing
+// the input when meaning the word, which is taken from the error model. It
+// requires the error model to be trained, see TrainErrorModel.
+//
+// Like with ContextWords every candidate within the edit distance is
+// considered, even if the input is in the dictionary. When used together with
+// ContextWords the probability of the word is taken from the context.
+func NoisyChannel(enabled bool) LookupOption {
+	return func(lp *lookupParams) error {
+		lp.noisyChannel = enabled
+		return nil
+	}
+}
+
+// lookupRanked finds every candidate for the input and ranks them by their
+// probability, given the context and the error model. Each suggestion holds the
+// probability it was ranked with.
+func (model *SpellModel) lookupRanked(input string, lookupParams *lookupParams) (utils.SuggestionList, error) {
+	if lookupParams.noisyChannel && !model.errorModel.Trained() {
+		return nil, errors.New("noisy channel ranking needs a trained error model")
+	}
+
+	// Every candidate is needed to rank them, so the level and the number of
+	// results are applied after ranking
+	level, maxResults := lookupParams.suggestionLevel, lookupParams.maxResults
+	lookupParams.suggestionLevel, lookupParams.maxResults = ALL, 0
+	results, err := model.lookup(input, lookupParams)
+	lookupParams.suggestionLevel, lookupParams.maxResults = level, maxResults
+
+	if err != nil || len(results) == 0 {
+		return results, err
+	}
+
+	inputRunes := []rune(input)
+	scores := make([]float64, len(results))
+	for i := range results {
+		scores[i] = model.logProbability(inputRunes, results[i], lookupParams)
+		results[i].Probability = math.Pow(10, scores[i])
+	}
+
+	// Sort the results and their scores together, keeping the order of the
+	// sort function for equal scores
+	order := make([]int, len(results))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return scores[order[i]] > scores[order[j]]
+	})
+
+	ranked := make(utils.SuggestionList, 0, len(results))
+	for _, i := range order {
+		if level == CLOSEST && results[i].Distance != results[order[0]].Distance {
+			continue
+		}
+		ranked = append(ranked, results[i])
+	}
+
+	if level == BEST {
+		return ranked[:1], nil
+	}
+
+	if maxResults > 0 && len(ranked) > maxResults {
+		ranked = ranked[:maxResults]
+	}
+
+	return ranked, nil
+}
+
+// logProbability returns the log10 probability of a suggestion being meant
+// when the input was typed
+func (model *SpellModel) logProbability(input []rune, suggestion utils.Suggestion, lookupParams *lookupParams) float64 {
+	dict := lookupParams.dictOpts.Name
+	context := lookupParams.context
+	score := 0.0
+
+	// The probability of the word
+	if context != nil && (context.previous != "" || context.next != "") {
+		if context.previous != "" {
+			score += math.Log10(model.conditionalProbability(dict, context.previous, suggestion.Word))
+		}
+		if context.next != "" {
+			score += math.Log10(model.conditionalProbability(dict, suggestion.Word, context.next))
+		}
+	} else {
+		cumulativeFreq := float64(atomic.LoadUint64(&model.cumulativeFreq))
+		score += math.Log10(float64(utils.Max(int(suggestion.Frequency), 1)) /
+			math.Max(cumulativeFreq, 1))
+	}
+
+	// The probability of typing the input when meaning the word
+	if lookupParams.noisyChannel {
+		score += math.Log10(model.errorModel.ProbabilityRunes(input, []rune(model.normalize(suggestion.Word))))
+	} else {
+		score -= contextEditPenalty * float64(suggestion.Distance)
+	}
+
+	return score
+}
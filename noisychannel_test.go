@@ -0,0 +1,113 @@
+package ta
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+func newWithErrorModel() *SpellModel {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Frequency: 100, Word: "cat"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 200, Word: "cut"})
+	s.AddMisspelling("bst", "bat")
+	s.AddMisspelling("hst", "hat")
+	s.AddMisspelling("mst", "mat")
+	return s
+}
+
+func ExampleNoisyChannel() {
+	s := newWithErrorModel()
+
+	// Without the error model the most frequent word is returned
+	suggestions, _ := s.Lookup("cst")
+	fmt.Println(suggestions)
+
+	// "s" is often typed instead of "a", so "cat" is more likely
+	suggestions, _ = s.Lookup("cst", NoisyChannel(true))
+	fmt.Println(suggestions)
+	// Output:
+	// [cut]
+	// [cat]
+}
+
+func TestLookup_noisyChannel(t *testing.T) {
+	s := newWithErrorModel()
+
+	suggestions, err := s.Lookup("cst", NoisyChannel(true), SuggestionLevel(ALL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[cat, cut]" {
+		t.Fatalf("Expected [cat, cut], got %v", suggestions)
+	}
+	if suggestions[0].Probability <= suggestions[1].Probability || suggestions[1].Probability <= 0 {
+		t.Fatalf("Unexpected probabilities %v and %v",
+			suggestions[0].Probability, suggestions[1].Probability)
+	}
+
+	// A word in the dictionary is usually typed correctly
+	suggestions, err = s.Lookup("cut", NoisyChannel(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[cut]" {
+		t.Fatalf("Expected [cut], got %v", suggestions)
+	}
+
+	if _, err := NewSpellModel().Lookup("cst", NoisyChannel(true)); err == nil {
+		t.Fatal("Expected an error without a trained error model")
+	}
+}
+
+func TestTrainErrorModel(t *testing.T) {
+	f, err := ioutil.TempFile("", "misspellings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	_, _ = f.WriteString("bst bat\n\nhst hat\n")
+	_ = f.Close()
+
+	s := NewSpellModel()
+	if _, err := s.TrainErrorModel(f.Name()); err != nil {
+		t.Fatal(err)
+	}
+	if s.errorModel.Pairs != 2 {
+		t.Fatalf("Expected 2 pairs, got %d", s.errorModel.Pairs)
+	}
+
+	_ = ioutil.WriteFile(f.Name(), []byte("bst\n"), 0644)
+	if _, err := s.TrainErrorModel(f.Name()); err == nil {
+		t.Fatal("Expected an error for a malformed line")
+	}
+}
+
+func TestSaveLoad_errorModel(t *testing.T) {
+	s1 := newWithErrorModel()
+
+	defer os.Remove("./test_errormodel.dump")
+	if err := s1.Save("./test_errormodel.dump"); err != nil {
+		t.Fatal(err)
+	}
+	s2, err := Load("./test_errormodel.dump")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s2.errorModel.Substitutions["sa"] != 3 {
+		t.Fatalf("Expected the error model to be loaded, got %v", s2.errorModel.Substitutions)
+	}
+
+	suggestions, err := s2.Lookup("cst", NoisyChannel(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[cat]" {
+		t.Fatalf("Expected [cat], got %v", suggestions)
+	}
+}
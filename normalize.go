@@ -0,0 +1,86 @@
+package ta
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+// SetNormalizer sets how words are normalized before they are indexed and
+// looked up, e.g. to ignore case or accents. Entries keep the spelling they
+// were added with, so suggestions return the dictionary spelling rather than
+// the normalized key. When several words share a key their frequencies are
+// added together and the spelling of the first word is kept.
+//
+// Words, bigrams and the error model already in the model are reindexed,
+// which is not safe while the model is being used. A nil normalizer leaves
+// words unchanged.
+func (model *SpellModel) SetNormalizer(normalizer *utils.Normalizer) error {
+	if err := normalizer.Validate(); err != nil {
+		return err
+	}
+
+	model.normalizer = normalizer
+	model.reindex()
+
+	return nil
+}
+
+// Normalizer returns the normalizer of the model, or nil if words are not
+// normalized
+func (model *SpellModel) Normalizer() *utils.Normalizer {
+	return model.normalizer
+}
+
+// normalize returns the key word is indexed with
+func (model *SpellModel) normalize(word string) string {
+	return model.normalizer.Normalize(word)
+}
+
+// reindex adds the words, bigrams and phonetic indexes of the model again and
+// rekeys its error model so that they are keyed with the current normalizer
+func (model *SpellModel) reindex() {
+	library := model.library
+	bigrams := model.bigrams
+	phonetics := model.phonetics
+
+	atomic.StoreUint64(&model.cumulativeFreq, 0)
+	atomic.StoreUint32(&model.longestWord, 0)
+	atomic.StoreUint32(&model.indexDepth, math.MaxUint32)
+	model.dictionaryDeletes = utils.NewDictionaryDeletes()
+	model.library = utils.NewLibrary()
+	model.bigrams = utils.NewBigrams()
+	model.phonetics = utils.NewPhoneticIndexes()
+	model.prefixes = utils.NewPrefixIndex()
+
+	for dict := range phonetics.Encoders() {
+		index, _ := phonetics.Load(dict)
+		_ = model.EnablePhoneticIndex(index.Encoder, DictionaryName(dict))
+	}
+
+	for dict := range library.Dictionaries {
+		// Add the words in order so that the spelling kept for words that
+		// share a key doesn't depend on map order
+		words := library.Words(dict)
+		sort.Strings(words)
+
+		for _, word := range words {
+			entry, _ := library.Load(dict, word)
+			_, _ = model.AddEntry(entry, DictionaryName(dict))
+		}
+	}
+
+	for dict, pairs := range bigrams.Dictionaries {
+		for key, count := range pairs {
+			words := strings.SplitN(key, " ", 2)
+			if len(words) == 2 {
+				_, _ = model.AddBigram(words[0], words[1], count, DictionaryName(dict))
+			}
+		}
+	}
+
+	model.errorModel.Rekey(model.normalize)
+}
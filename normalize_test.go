@@ -0,0 +1,124 @@
+package ta
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+func ExampleSpellModel_SetNormalizer() {
+	s := NewSpellModel()
+	_ = s.SetNormalizer(&utils.Normalizer{Form: utils.NFC, CaseFold: true, FoldDiacritics: true})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "Café"})
+
+	// The key is normalized but the dictionary spelling is returned
+	suggestions, _ := s.Lookup("CAFE")
+	fmt.Println(suggestions, suggestions[0].Distance)
+	// Output:
+	// [Café] 0
+}
+
+func TestSetNormalizer(t *testing.T) {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "Word"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 5, Word: "word"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 3, Word: "café"})
+	_, _ = s.AddBigram("Word", "café", 2)
+
+	if suggestions, _ := s.Lookup("WORD", EditDistance(0)); len(suggestions) != 0 {
+		t.Fatalf("Expected no suggestions without a normalizer, got %v", suggestions)
+	}
+
+	if err := s.SetNormalizer(&utils.Normalizer{Form: "NFX"}); err == nil {
+		t.Fatal("Expected an error for an unknown form")
+	}
+
+	// Existing words are reindexed and words sharing a key are merged
+	if err := s.SetNormalizer(&utils.Normalizer{Form: utils.NFC, CaseFold: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	suggestions, err := s.Lookup("WORD", EditDistance(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[Word]" || suggestions[0].Frequency != 15 {
+		t.Fatalf("Expected [Word] with frequency 15, got %v", suggestions)
+	}
+
+	// Decomposed input matches the composed word
+	if entry, _ := s.GetEntry("CAFE\u0301"); entry == nil || entry.Word != "café" {
+		t.Fatalf("Expected entry café, got %v", entry)
+	}
+
+	if count, _ := s.GetBigram("word", "CAF\u00c9"); count != 2 {
+		t.Fatalf("Expected count 2, got %d", count)
+	}
+
+	_, _ = s.AddEntry(utils.Entry{Frequency: 1, Word: "WORD"})
+	if entry, _ := s.GetEntry("word"); entry.Word != "Word" || entry.Frequency != 16 {
+		t.Fatalf("Expected the first spelling to be kept, got %v", entry)
+	}
+
+	if ok, _ := s.RemoveEntry("WoRd"); !ok {
+		t.Fatal("Expected the entry to be removed")
+	}
+}
+
+func TestSegment_normalizer(t *testing.T) {
+	s := NewSpellModel()
+	_ = s.SetNormalizer(&utils.Normalizer{CaseFold: true})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "the"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "Cat"})
+
+	result, err := s.Segment("TheCat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.String() != "the Cat" || result.Distance != 1 {
+		t.Fatalf("Expected the Cat with distance 1, got %v (%d)", result, result.Distance)
+	}
+}
+
+func TestSaveLoad_normalizer(t *testing.T) {
+	s1 := NewSpellModel()
+	_ = s1.SetNormalizer(&utils.Normalizer{CaseFold: true, Locale: "tr"})
+	_, _ = s1.AddEntry(utils.Entry{Frequency: 1, Word: "Istanbul"})
+
+	defer os.Remove("./test_normalizer.dump")
+	if err := s1.Save("./test_normalizer.dump"); err != nil {
+		t.Fatal(err)
+	}
+	s2, err := Load("./test_normalizer.dump")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s2.Normalizer() == nil || s2.Normalizer().Locale != "tr" {
+		t.Fatalf("Expected the normalizer to be loaded, got %v", s2.Normalizer())
+	}
+	if entry, _ := s2.GetEntry("ıstanbul"); entry == nil || entry.Word != "Istanbul" {
+		t.Fatalf("Expected entry Istanbul, got %v", entry)
+	}
+}
+
+func TestSetNormalizer_errorModel(t *testing.T) {
+	s1 := NewSpellModel()
+	s1.AddMisspelling("Teh", "The")
+	s1.AddMisspelling("THSI", "THIS")
+	_ = s1.SetNormalizer(&utils.Normalizer{CaseFold: true})
+
+	s2 := NewSpellModel()
+	_ = s2.SetNormalizer(&utils.Normalizer{CaseFold: true})
+	s2.AddMisspelling("Teh", "The")
+	s2.AddMisspelling("THSI", "THIS")
+
+	if p1, p2 := s1.errorModel.Probability("thsi", "this"), s2.errorModel.Probability("thsi", "this"); p1 != p2 {
+		t.Fatalf("Expected probability %v once reindexed, got %v", p2, p1)
+	}
+	if count := s1.errorModel.Transpositions["is"]; count != 1 {
+		t.Fatalf("Expected transposition is to be counted once, got %d", count)
+	}
+}
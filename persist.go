@@ -0,0 +1,334 @@
+package ta
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+// EntryError is a problem with a word of a dictionary while a model is written
+// or read
+type EntryError struct {
+	Dictionary string
+	Word       string
+	Err        error
+}
+
+// Error returns the dictionary and word involved followed by the problem
+func (e *EntryError) Error() string {
+	return fmt.Sprintf("dictionary %q, word %q: %v", e.Dictionary, e.Word, e.Err)
+}
+
+// Unwrap returns the underlying problem
+func (e *EntryError) Unwrap() error {
+	return e.Err
+}
+
+type persistedOptions struct {
+	EditDistance *uint32           `json:"editDistance"`
+	PrefixLength *uint32           `json:"prefixLength"`
+	Phonetic     map[string]string `json:"phonetic"`
+	Normalizer   *utils.Normalizer `json:"normalizer"`
+}
+
+// WriteTo writes the model to w as JSON, one word at a time, and returns the
+// number of bytes written. The options come first so that ReadFrom can apply
+// them before it adds the words.
+func (model *SpellModel) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+
+	options, err := json.Marshal(persistedOptions{
+		EditDistance: &model.MaxEditDistance,
+		PrefixLength: &model.PrefixLength,
+		Phonetic:     model.phonetics.Encoders(),
+		Normalizer:   model.normalizer,
+	})
+	if err != nil {
+		return cw.n, fmt.Errorf("writing options: %w", err)
+	}
+
+	model.bigrams.RLock()
+	bigrams, err := json.Marshal(model.bigrams.Dictionaries)
+	model.bigrams.RUnlock()
+	if err != nil {
+		return cw.n, fmt.Errorf("writing bigrams: %w", err)
+	}
+
+	model.errorModel.RLock()
+	errorModel, err := json.Marshal(model.errorModel)
+	model.errorModel.RUnlock()
+	if err != nil {
+		return cw.n, fmt.Errorf("writing error model: %w", err)
+	}
+
+	bw.WriteString(`{"options":`)
+	bw.Write(options)
+	bw.WriteString(`,"words":{`)
+
+	dicts := model.library.Names()
+	sort.Strings(dicts)
+
+	for i, dict := range dicts {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		writeJSONKey(bw, dict)
+		bw.WriteByte('{')
+
+		words := model.library.Words(dict)
+		sort.Strings(words)
+
+		first := true
+		for _, word := range words {
+			entry, exists := model.library.Load(dict, word)
+			if !exists {
+				continue
+			}
+
+			data, err := json.Marshal(entry)
+			if err != nil {
+				return cw.n, &EntryError{Dictionary: dict, Word: entry.Word, Err: err}
+			}
+
+			if !first {
+				bw.WriteByte(',')
+			}
+			first = false
+
+			writeJSONKey(bw, word)
+			if _, err := bw.Write(data); err != nil {
+				return cw.n, err
+			}
+		}
+
+		bw.WriteByte('}')
+	}
+
+	bw.WriteString(`},"bigrams":`)
+	bw.Write(bigrams)
+	bw.WriteString(`,"errorModel":`)
+	bw.Write(errorModel)
+	bw.WriteByte('}')
+
+	// bufio.Writer keeps the first error, so checking on flush is enough
+	err = bw.Flush()
+	return cw.n, err
+}
+
+// ReadFrom reads a model written by WriteTo from r into an empty model, one
+// word at a time, and returns the number of bytes read. A word that can't be
+// read or added stops with an EntryError. Anything but whitespace after the
+// model is an error.
+func (model *SpellModel) ReadFrom(r io.Reader) (int64, error) {
+	cr := &countingReader{r: r}
+	dec := json.NewDecoder(cr)
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return cr.n, err
+	}
+
+	var optionsRead bool
+	var pendingWords json.RawMessage
+
+	for dec.More() {
+		key, err := readJSONKey(dec)
+		if err != nil {
+			return cr.n, err
+		}
+
+		switch key {
+		case "options":
+			options := persistedOptions{}
+			if err := dec.Decode(&options); err != nil {
+				return cr.n, fmt.Errorf("reading options: %w", err)
+			}
+			if err := model.applyOptions(options); err != nil {
+				return cr.n, err
+			}
+			optionsRead = true
+		case "words":
+			// Words are keyed with the options, so they wait for options that
+			// come after them
+			if !optionsRead {
+				if err := dec.Decode(&pendingWords); err != nil {
+					return cr.n, fmt.Errorf("reading words: %w", err)
+				}
+				continue
+			}
+			if err := model.readWords(dec); err != nil {
+				return cr.n, err
+			}
+		case "bigrams":
+			bigrams := make(map[string]map[string]uint64)
+			if err := dec.Decode(&bigrams); err != nil {
+				return cr.n, fmt.Errorf("reading bigrams: %w", err)
+			}
+			for dict, counts := range bigrams {
+				for key, count := range counts {
+					words := strings.SplitN(key, " ", 2)
+					if len(words) == 2 {
+						model.bigrams.Store(dict, words[0], words[1], count)
+					}
+				}
+			}
+		case "errorModel":
+			if err := dec.Decode(model.errorModel); err != nil {
+				return cr.n, fmt.Errorf("reading error model: %w", err)
+			}
+		default:
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return cr.n, fmt.Errorf("reading %s: %w", key, err)
+			}
+		}
+	}
+
+	if err := expectDelim(dec, '}'); err != nil {
+		return cr.n, err
+	}
+
+	// Read r to the end, so that a reader that checks its data when it ends,
+	// such as gzip, reports a corrupt model
+	if token, err := dec.Token(); err == nil {
+		return cr.n, fmt.Errorf("unexpected %v after the model", token)
+	} else if err != io.EOF {
+		return cr.n, err
+	}
+
+	if pendingWords != nil {
+		if err := model.readWords(json.NewDecoder(bytes.NewReader(pendingWords))); err != nil {
+			return cr.n, err
+		}
+	}
+
+	return cr.n, nil
+}
+
+// applyOptions sets the options of a model read by ReadFrom
+func (model *SpellModel) applyOptions(options persistedOptions) error {
+	if options.Normalizer != nil {
+		if err := model.SetNormalizer(options.Normalizer); err != nil {
+			return err
+		}
+	}
+
+	if options.EditDistance != nil {
+		model.MaxEditDistance = *options.EditDistance
+	}
+
+	if options.PrefixLength != nil {
+		model.PrefixLength = *options.PrefixLength
+	}
+
+	for dict, name := range options.Phonetic {
+		encoder, exists := utils.GetPhoneticEncoder(name)
+		if !exists {
+			return fmt.Errorf("unknown phonetic encoder %q", name)
+		}
+		if err := model.EnablePhoneticIndex(encoder, DictionaryName(dict)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readWords adds the words of each dictionary as they are decoded
+func (model *SpellModel) readWords(dec *json.Decoder) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return fmt.Errorf("reading words: %w", err)
+	}
+
+	for dec.More() {
+		dict, err := readJSONKey(dec)
+		if err != nil {
+			return fmt.Errorf("reading words: %w", err)
+		}
+		if err := expectDelim(dec, '{'); err != nil {
+			return fmt.Errorf("reading dictionary %q: %w", dict, err)
+		}
+
+		for dec.More() {
+			word, err := readJSONKey(dec)
+			if err != nil {
+				return fmt.Errorf("reading dictionary %q: %w", dict, err)
+			}
+
+			entry := utils.Entry{}
+			if err := dec.Decode(&entry); err != nil {
+				return &EntryError{Dictionary: dict, Word: word, Err: err}
+			}
+
+			if _, err := model.AddEntry(entry, DictionaryName(dict)); err != nil {
+				return &EntryError{Dictionary: dict, Word: word, Err: err}
+			}
+		}
+
+		if err := expectDelim(dec, '}'); err != nil {
+			return fmt.Errorf("reading dictionary %q: %w", dict, err)
+		}
+	}
+
+	return expectDelim(dec, '}')
+}
+
+// expectDelim reads the next token and checks it is the delimiter delim
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %v, got %v", delim, token)
+	}
+	return nil
+}
+
+// readJSONKey reads the next key of an object
+func readJSONKey(dec *json.Decoder) (string, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := token.(string)
+	if !ok {
+		return "", fmt.Errorf("expected a key, got %v", token)
+	}
+	return key, nil
+}
+
+// writeJSONKey writes key followed by a colon
+func writeJSONKey(bw *bufio.Writer, key string) {
+	data, _ := json.Marshal(key)
+	bw.Write(data)
+	bw.WriteByte(':')
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
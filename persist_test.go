@@ -0,0 +1,184 @@
+package ta
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+func ExampleSpellModel_WriteTo() {
+	s1 := NewSpellModel()
+	_, _ = s1.AddEntry(utils.Entry{Word: "example", Frequency: 1})
+
+	var buf bytes.Buffer
+	_, _ = s1.WriteTo(&buf)
+
+	s2 := NewSpellModel()
+	_, _ = s2.ReadFrom(&buf)
+
+	suggestions, _ := s2.Lookup("eample")
+	fmt.Println(suggestions)
+	// Output:
+	// [example]
+}
+
+func TestWriteTo_readFrom(t *testing.T) {
+	s1 := NewSpellModel()
+	s1.MaxEditDistance = 3
+	_, _ = s1.AddEntry(utils.Entry{Word: "example", Frequency: 2,
+		WordData: utils.WordData{"pos": "noun"}}, DictionaryName("en"))
+	_, _ = s1.AddEntry(utils.Entry{Word: "beispiel", Frequency: 1}, DictionaryName("de"))
+
+	var buf bytes.Buffer
+	written, err := s1.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != int64(buf.Len()) {
+		t.Fatalf("Expected %d bytes written, got %d", buf.Len(), written)
+	}
+
+	size := buf.Len()
+	s2 := NewSpellModel()
+	read, err := s2.ReadFrom(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read != int64(size) {
+		t.Fatalf("Expected %d bytes read, got %d", size, read)
+	}
+
+	// The deletes were generated with the edit distance of the saved model
+	suggestions, _ := s2.Lookup("xmpl", EditDistance(3), DictionaryOpts(DictionaryName("en")))
+	if len(suggestions) != 1 || suggestions[0].WordData["pos"] != "noun" {
+		t.Fatalf("Expected example with its word data, got %v", suggestions)
+	}
+	if entry, _ := s2.GetEntry("beispiel", DictionaryName("de")); entry == nil {
+		t.Fatal("Expected beispiel in the de dictionary")
+	}
+}
+
+func TestReadFrom_optionsLast(t *testing.T) {
+	data := `{"words":{"default":{"example":{"Frequency":1,"Word":"example"}}},"options":{"editDistance":3}}`
+
+	s := NewSpellModel()
+	if _, err := s.ReadFrom(strings.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+
+	suggestions, _ := s.Lookup("xmpl", EditDistance(3))
+	if len(suggestions) != 1 {
+		t.Fatalf("Expected example at distance 3, got %v", suggestions)
+	}
+}
+
+func TestReadFrom_errors(t *testing.T) {
+	data := `{"options":{},"words":{"en":{"cat":{"Frequency":1,"Word":"cat"},"dog":{"Frequency":"many"}}}}`
+
+	s := NewSpellModel()
+	_, err := s.ReadFrom(strings.NewReader(data))
+	var entryErr *EntryError
+	if !errors.As(err, &entryErr) || entryErr.Dictionary != "en" || entryErr.Word != "dog" {
+		t.Fatalf("Expected an entry error for dog in en, got %v", err)
+	}
+
+	for _, data := range []string{"", "[]", `{"words":`, `{"words":{"en":[]}}`, `{} {}`, `{"options":{}} x`} {
+		if _, err := NewSpellModel().ReadFrom(strings.NewReader(data)); err == nil {
+			t.Fatalf("Expected an error reading %q", data)
+		}
+	}
+}
+
+func TestWriteTo_errors(t *testing.T) {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Word: "nan", Frequency: 1,
+		WordData: utils.WordData{"value": math.NaN()}}, DictionaryName("en"))
+
+	_, err := s.WriteTo(&bytes.Buffer{})
+	var entryErr *EntryError
+	if !errors.As(err, &entryErr) || entryErr.Dictionary != "en" || entryErr.Word != "nan" {
+		t.Fatalf("Expected an entry error for nan in en, got %v", err)
+	}
+}
+
+// Run with -race to check the error model is read under its lock
+func TestWriteTo_training(t *testing.T) {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Frequency: 1, Word: "the"})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			s.AddMisspelling("teh", "the")
+		}
+	}()
+
+	for i := 0; i < 10; i++ {
+		if _, err := s.WriteTo(ioutil.Discard); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+}
+
+func TestLoad_dictModel(t *testing.T) {
+	s, err := Load(filepath.Join("main", "dict.model"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	suggestions, err := s.Lookup("wrd", SuggestionLevel(ALL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[word, world]" || suggestions[0].WordData["type"] != "noun" {
+		t.Fatalf("Expected [word, world] with word data, got %v", suggestions)
+	}
+}
+
+func TestLoad_corrupt(t *testing.T) {
+	s, err := newWithExample()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "model")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.model")
+	if err := s.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The gzip trailer holds the checksum and then the size of the data
+	checksum := append([]byte{}, data...)
+	checksum[len(checksum)-8] ^= 0xff
+
+	for name, corrupt := range map[string][]byte{
+		"checksum":  checksum,
+		"truncated": data[:len(data)-4],
+	} {
+		if err := ioutil.WriteFile(path, corrupt, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Fatalf("Expected an error loading a model with a bad %s", name)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package ta
+
+import (
+	"errors"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+// EnablePhoneticIndex creates a phonetic index for a dictionary with the given
+// encoder, e.g. utils.DoubleMetaphone{}, replacing any existing index. Words
+// already in the dictionary are indexed, and the index is kept up to date as
+// entries are added and removed.
+func (model *SpellModel) EnablePhoneticIndex(encoder utils.PhoneticEncoder, opts ...utils.DictionaryOption) error {
+	dictOpts := model.defaultDictOptions()
+
+	for _, opt := range opts {
+		if err := opt(dictOpts); err != nil {
+			return err
+		}
+	}
+
+	if encoder == nil {
+		return errors.New("phonetic encoder must not be nil")
+	}
+
+	index := utils.NewPhoneticIndex(encoder)
+	for _, word := range model.library.Words(dictOpts.Name) {
+		index.Add(word)
+	}
+
+	model.phonetics.Store(dictOpts.Name, index)
+
+	return nil
+}
+
+// DisablePhoneticIndex removes the phonetic index of a dictionary
+func (model *SpellModel) DisablePhoneticIndex(opts ...utils.DictionaryOption) error {
+	dictOpts := model.defaultDictOptions()
+
+	for _, opt := range opts {
+		if err := opt(dictOpts); err != nil {
+			return err
+		}
+	}
+
+	model.phonetics.Delete(dictOpts.Name)
+
+	return nil
+}
+
+// PhoneticSuggestions defines whether words that sound like the input should
+// be merged into the suggestions. It requires a phonetic index on the
+// dictionary, see EnablePhoneticIndex. Sound-alike words may be further away
+// than the edit distance; they are flagged with Phonetic and ranked by their
+// real distance. BEST and CLOSEST only use them when no closer word is found.
+func PhoneticSuggestions(include bool) LookupOption {
+	return func(lp *lookupParams) error {
+		lp.phonetic = include
+		return nil
+	}
+}
+
+// mergePhonetic adds the words that sound like the input to the results
+func (model *SpellModel) mergePhonetic(input string, results utils.SuggestionList, lookupParams *lookupParams) utils.SuggestionList {
+	index, exists := model.phonetics.Load(lookupParams.dictOpts.Name)
+	if !exists {
+		return results
+	}
+
+	if lookupParams.suggestionLevel != ALL && len(results) > 0 {
+		return results
+	}
+
+	found := make(map[string]struct{}, len(results))
+	for _, suggestion := range results {
+		found[model.normalize(suggestion.Word)] = struct{}{}
+	}
+
+	inputRunes := []rune(input)
+	var phonetic utils.SuggestionList
+
+	for _, word := range index.Load(input) {
+		if _, exists := found[word]; exists || word == input {
+			continue
+		}
+
+		if entry, _ := model.library.Load(lookupParams.dictOpts.Name, word); !lookupParams.accepts(entry) {
+			continue
+		}
+
+		wordRunes := []rune(word)
+		maxDist := utils.Max(len(inputRunes), len(wordRunes))
+		if lookupParams.minSimilarity > 0 {
+			maxDist = lookupParams.similarDistance(len(inputRunes), len(wordRunes))
+		}
+
+		// The cost is measured like that of the other suggestions, so that
+		// they sort together
+		dist, cost, ok := lookupParams.measure(inputRunes, wordRunes, maxDist)
+		if !ok {
+			continue
+		}
+
+		suggestion := model.newDictSuggestion(word, dist, lookupParams.dictOpts)
+		suggestion.Cost = cost
+		suggestion.Phonetic = true
+		phonetic = append(phonetic, suggestion)
+	}
+
+	if len(phonetic) == 0 {
+		return results
+	}
+
+	lookupParams.sortFunc(phonetic)
+
+	switch lookupParams.suggestionLevel {
+	case BEST:
+		phonetic = phonetic[:1]
+	case CLOSEST:
+		closest := 1
+		for closest < len(phonetic) && phonetic[closest].Distance == phonetic[0].Distance {
+			closest++
+		}
+		phonetic = phonetic[:closest]
+	}
+
+	return append(results, phonetic...)
+}
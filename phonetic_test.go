@@ -0,0 +1,149 @@
+package ta
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+func ExamplePhoneticSuggestions() {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "phonetic"})
+	_ = s.EnablePhoneticIndex(utils.DoubleMetaphone{})
+
+	// The word is too far away for the edit distance, but sounds the same
+	suggestions, _ := s.Lookup("fonetik")
+	fmt.Println(suggestions)
+
+	suggestions, _ = s.Lookup("fonetik", PhoneticSuggestions(true))
+	fmt.Println(suggestions, suggestions[0].Phonetic)
+	// Output:
+	// []
+	// [phonetic] true
+}
+
+func newWithPhonetic() *SpellModel {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "knowledge"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 5, Word: "college"})
+	_ = s.EnablePhoneticIndex(utils.DoubleMetaphone{})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "phonetic"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 1, Word: "fanatic"})
+	return s
+}
+
+func TestLookup_phonetic(t *testing.T) {
+	s := newWithPhonetic()
+
+	suggestions, err := s.Lookup("nollij", PhoneticSuggestions(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[knowledge]" {
+		t.Fatal(fmt.Sprintf("Expected [knowledge], got %v", suggestions))
+	}
+
+	// Words found by edit distance are preferred for BEST
+	suggestions, err = s.Lookup("fanatik", PhoneticSuggestions(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[fanatic]" || suggestions[0].Phonetic {
+		t.Fatal(fmt.Sprintf("Expected [fanatic] by edit distance, got %v", suggestions))
+	}
+
+	// ALL merges both
+	suggestions, err = s.Lookup("fanatik", PhoneticSuggestions(true), SuggestionLevel(ALL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[fanatic, phonetic]" || !suggestions[1].Phonetic {
+		t.Fatal(fmt.Sprintf("Expected [fanatic, phonetic], got %v", suggestions))
+	}
+
+	// Removed words leave the index
+	_, _ = s.RemoveEntry("knowledge")
+	suggestions, err = s.Lookup("nollij", PhoneticSuggestions(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 0 {
+		t.Fatal(fmt.Sprintf("Expected no suggestions, got %v", suggestions))
+	}
+}
+
+func TestLookup_phoneticCost(t *testing.T) {
+	s := newWithPhonetic()
+
+	// A weighted distance that makes every edit cost half
+	half := func(r1, r2 []rune, maxCost float64) float64 {
+		dist := utils.DamerauLevenshteinRunes(r1, r2, int(2*maxCost))
+		if dist < 0 {
+			return -1
+		}
+		return float64(dist) / 2
+	}
+
+	suggestions, err := s.Lookup("fanatik", PhoneticSuggestions(true), SuggestionLevel(ALL),
+		WeightedDistanceFunc(half))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[fanatic, phonetic]" {
+		t.Fatal(fmt.Sprintf("Expected [fanatic, phonetic], got %v", suggestions))
+	}
+
+	// The phonetic suggestion is measured with the same weighted distance
+	want := float64(utils.DamerauLevenshtein("fanatik", "phonetic", 10)) / 2
+	if suggestions[0].Cost != 0.5 || suggestions[1].Cost != want {
+		t.Fatal(fmt.Sprintf("Expected costs 0.5 and %v, got %v and %v",
+			want, suggestions[0].Cost, suggestions[1].Cost))
+	}
+}
+
+func TestLookup_phoneticWithoutIndex(t *testing.T) {
+	s := newWithPhonetic()
+	_ = s.DisablePhoneticIndex()
+
+	suggestions, err := s.Lookup("nollij", PhoneticSuggestions(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 0 {
+		t.Fatal("Expected no suggestions without a phonetic index")
+	}
+}
+
+func TestSaveLoad_phonetic(t *testing.T) {
+	s1 := newWithPhonetic()
+	_, _ = s1.AddEntry(utils.Entry{Frequency: 1, Word: "Robert"}, DictionaryName("names"))
+	_ = s1.EnablePhoneticIndex(utils.Soundex{}, DictionaryName("names"))
+
+	defer os.Remove("./test_phonetic.dump")
+	if err := s1.Save("./test_phonetic.dump"); err != nil {
+		t.Fatal(err)
+	}
+	s2, err := Load("./test_phonetic.dump")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	suggestions, err := s2.Lookup("nollij", PhoneticSuggestions(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[knowledge]" {
+		t.Fatal(fmt.Sprintf("Expected [knowledge], got %v", suggestions))
+	}
+
+	suggestions, err = s2.Lookup("Rupurt", PhoneticSuggestions(true),
+		DictionaryOpts(DictionaryName("names")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[Robert]" {
+		t.Fatal(fmt.Sprintf("Expected [Robert], got %v", suggestions))
+	}
+}
@@ -0,0 +1,93 @@
+package ta
+
+import (
+	"errors"
+	"math"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+// SubstitutionRules looks up the input with a table of multi-rune substitution
+// rules, such as utils.OCRRuleSet, where each rule counts as a single edit of
+// its own cost. The rules are applied to the input to find words that plain
+// edits can't reach, e.g. "modem" from "rnodern" within 2 edits, and the
+// distance to each word is measured with the rules instead of the distance
+// function.
+func SubstitutionRules(rules *utils.RuleSet) LookupOption {
+	return func(lp *lookupParams) error {
+		if rules == nil {
+			return errors.New("substitution rules must not be nil")
+		}
+		lp.rules = rules
+		return nil
+	}
+}
+
+// lookupRules looks up each variant the substitution rules turn the input
+// into, and measures the distance of the words found to the input
+func (model *SpellModel) lookupRules(input string, lookupParams *lookupParams) (utils.SuggestionList, error) {
+	rules := lookupParams.rules
+	editDistance := int(lookupParams.editDistance)
+	inputRunes := []rune(input)
+
+	// Every word within the edits left after the rules of a variant is a
+	// candidate, so the level and the number of results are applied after the
+	// distances to the input are known
+	variantParams := *lookupParams
+	variantParams.rules = nil
+	variantParams.suggestionLevel = ALL
+	variantParams.maxResults = 0
+	variantParams.minSimilarity = 0
+	variantParams.phonetic = false
+	variantParams.costFunction = nil
+	variantParams.distanceFunction = rules.Runes
+
+	found := make(map[string]struct{})
+	results := utils.SuggestionList{}
+
+	for variant, cost := range rules.Variants(input, float64(editDistance)) {
+		if stop, err := lookupParams.interrupted(); err != nil {
+			return nil, err
+		} else if stop {
+			break
+		}
+
+		// Allow for rounding errors when rule costs add up to a whole number
+		variantParams.editDistance = uint32(math.Floor(float64(editDistance) - cost + 1e-9))
+
+		suggestions, err := model.lookup(variant, &variantParams)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, suggestion := range suggestions {
+			word := model.normalize(suggestion.Word)
+			if !utils.AddKey(found, word) {
+				continue
+			}
+
+			wordRunes := []rune(word)
+			maxDist := editDistance
+			if lookupParams.minSimilarity > 0 {
+				maxDist = utils.Min(maxDist, lookupParams.similarDistance(len(inputRunes), len(wordRunes)))
+			}
+
+			cost := rules.WeightedRunes(inputRunes, wordRunes, float64(maxDist))
+			if cost < 0 {
+				continue
+			}
+
+			result := model.newDictSuggestion(word, int(math.Ceil(cost-1e-9)), lookupParams.dictOpts)
+			result.Cost = cost
+			results = append(results, result)
+		}
+	}
+
+	if lookupParams.phonetic {
+		results = model.mergePhonetic(input, results, lookupParams)
+	}
+
+	lookupParams.sortFunc(results)
+
+	return lookupParams.limitResults(results), nil
+}
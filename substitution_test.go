@@ -0,0 +1,83 @@
+package ta
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/agusnavce/ta/utils"
+)
+
+func newWithOCRWords() *SpellModel {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "modem"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 50, Word: "modern"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 20, Word: "dog"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 5, Word: "home"})
+	return s
+}
+
+func ExampleSubstitutionRules() {
+	s := newWithOCRWords()
+
+	suggestions, _ := s.Lookup("rnodern", SubstitutionRules(utils.OCRRuleSet()),
+		SuggestionLevel(ALL))
+	for _, suggestion := range suggestions {
+		fmt.Println(suggestion.Word, suggestion.Distance)
+	}
+	// Output:
+	// modern 1
+	// modem 2
+}
+
+func TestLookup_substitutionRules(t *testing.T) {
+	s := newWithOCRWords()
+
+	// Without the rules "modem" is too far away
+	suggestions, err := s.Lookup("rnodern", SuggestionLevel(ALL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[modern]" {
+		t.Fatalf("Expected [modern], got %v", suggestions)
+	}
+
+	rules := SubstitutionRules(utils.OCRRuleSet())
+
+	suggestions, err = s.Lookup("clog", rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[dog]" || suggestions[0].Distance != 1 {
+		t.Fatalf("Expected [dog] at distance 1, got %v", suggestions)
+	}
+
+	suggestions, err = s.Lookup("rnodem", rules, SuggestionLevel(CLOSEST))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[modem]" {
+		t.Fatalf("Expected [modem], got %v", suggestions)
+	}
+
+	suggestions, err = s.Lookup("rnodern", rules, EditDistance(1), SuggestionLevel(ALL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[modern]" {
+		t.Fatalf("Expected [modern], got %v", suggestions)
+	}
+
+	suggestions, err = s.Lookup("liome", rules, Filter(func(e utils.Entry) bool {
+		return e.Word != "home"
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 0 {
+		t.Fatalf("Expected no suggestions, got %v", suggestions)
+	}
+
+	if _, err := s.Lookup("rnodern", SubstitutionRules(nil)); err == nil {
+		t.Fatal("Expected an error for nil rules")
+	}
+}
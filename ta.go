@@ -4,20 +4,20 @@ package ta
 import (
 	"bufio"
 	"compress/gzip"
-	"encoding/json"
+	"context"
 	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
-	"log"
 	"math"
 	"os"
 	"sort"
 	"strings"
 	"sync/atomic"
+	"time"
 	"unicode"
 
 	"github.com/agusnavce/ta/utils"
-	"github.com/mitchellh/mapstructure"
-	"github.com/tidwall/gjson"
 )
 
 type suggestionLevel int
@@ -42,6 +42,14 @@ type SpellModel struct {
 	dictionaryDeletes *utils.DictionaryDeletes
 	longestWord uint32
 	library *utils.Library
+	bigrams *utils.Bigrams
+	phonetics *utils.PhoneticIndexes
+	errorModel *utils.ErrorModel
+	normalizer *utils.Normalizer
+	prefixes *utils.PrefixIndex
+	// The max edit distance the deletes of every word were generated with,
+	// lower than MaxEditDistance if it was raised after words were added
+	indexDepth uint32
 }
 
 // Main constants
@@ -53,59 +61,32 @@ const (
 
 // Load a dictionary from disk from filename. Returns a new Spell instance on
 // success, or will return an error if there's a problem reading the file.
-func Load(filename string) (*SpellModel, error) {
-	s := NewSpellModel()
-
+func Load(filename string) (_ *SpellModel, err error) {
 	f, err := os.Open(filename)
 	if err != nil {
 		return nil, err
 	}
+	defer f.Close()
 
 	gz, err := gzip.NewReader(f)
 	if err != nil {
 		return nil, err
 	}
 
-	data, err := ioutil.ReadAll(gz)
-	if err != nil {
+	s := NewSpellModel()
+	if _, err := s.ReadFrom(gz); err != nil {
 		return nil, err
 	}
 
-	err = f.Close()
-	if err != nil {
+	// The checksum of the file is only verified once it has been read to the
+	// end
+	if _, err := io.Copy(ioutil.Discard, gz); err != nil {
 		return nil, err
 	}
-
-	err = gz.Close()
-	if err != nil {
+	if err := gz.Close(); err != nil {
 		return nil, err
 	}
 
-	// Load the words
-	gj := gjson.ParseBytes(data)
-	gj.Get("words").ForEach(func(dictionary, entries gjson.Result) bool {
-		entries.ForEach(func(word, definition gjson.Result) bool {
-			e := utils.Entry{}
-			if err := mapstructure.Decode(definition.Value(), &e); err != nil {
-				log.Fatal(err)
-			}
-
-			if _, err := s.AddEntry(e); err != nil {
-				log.Fatal(err)
-			}
-			return true
-		})
-		return true
-	})
-
-	if gj.Get("options.editDistance").Exists() {
-		s.MaxEditDistance = uint32(gj.Get("options.editDistance").Int())
-	}
-
-	if gj.Get("options.prefixLength").Exists() {
-		s.PrefixLength = uint32(gj.Get("options.prefixLength").Int())
-	}
-
 	return s, nil
 }
 
@@ -124,6 +105,11 @@ func (model *SpellModel) Init() *SpellModel {
 	s.MaxEditDistance = defaultEditDistance
 	s.PrefixLength = defaultPrefixLength
 	s.library = utils.NewLibrary()
+	s.bigrams = utils.NewBigrams()
+	s.phonetics = utils.NewPhoneticIndexes()
+	s.errorModel = utils.NewErrorModel()
+	s.prefixes = utils.NewPrefixIndex()
+	s.indexDepth = math.MaxUint32
 	return s
 }
 
@@ -139,7 +125,7 @@ func (model *SpellModel) AddEntry(de utils.Entry, opts ...utils.DictionaryOption
 		}
 	}
 
-	word := de.Word
+	word := model.normalize(de.Word)
 
 	atomic.AddUint64(&model.cumulativeFreq, de.Frequency)
 
@@ -153,11 +139,18 @@ func (model *SpellModel) AddEntry(de utils.Entry, opts ...utils.DictionaryOption
 		if !dictOptions.OverrideWordData {
 			de.WordData = entry.WordData
 		}
+		// Keep the spelling the word was first added with
+		de.Word = entry.Word
 		model.library.Store(dictOptions.Name, word, de)
 		return false, nil
 	}
 
 	model.library.Store(dictOptions.Name, word, de)
+	model.prefixes.Add(dictOptions.Name, word)
+
+	if index, exists := model.phonetics.Load(dictOptions.Name); exists {
+		index.Add(word)
+	}
 
 	// Keep track of the longest word in the dictionary
 	wordLength := uint32(len([]rune(word)))
@@ -224,7 +217,8 @@ func (model *SpellModel) AddEntries(entries utils.Entries,  opts ...utils.Dictio
 
 
 // CreateDictionary loads multiple dictionary entries from a file of
-// words. Merges with any dictionary data already loaded.
+// words, with a frequency of 1 each. Merges with any dictionary data already
+// loaded. See ImportFrequencies for files that hold counts.
 func (model *SpellModel) CreateDictionary(filePath string, opts ...utils.DictionaryOption) (bool, error) {
 	dictOpts := model.defaultDictOptions()
 
@@ -235,24 +229,23 @@ func (model *SpellModel) CreateDictionary(filePath string, opts ...utils.Diction
 	}
 
 	f, err := os.Open(filePath)
-
 	if err != nil {
 		return false, err
 	}
+	defer f.Close()
 
 	s := bufio.NewScanner(f)
 
 	for s.Scan() {
-		model.AddEntry(utils.Entry{
-			Frequency: 1, 
-			Word: s.Text(),
-		})
+		if _, err := model.AddEntry(utils.Entry{
+			Frequency: 1,
+			Word:      s.Text(),
+		}, opts...); err != nil {
+			return false, err
+		}
 	}
-		
-	err = s.Err()
-	err = f.Close()
 
-	if  err != nil {
+	if err := s.Err(); err != nil {
 		return false, err
 	}
 
@@ -304,7 +297,7 @@ func (model *SpellModel) GetEntry(word string, opts ...utils.DictionaryOption) (
 		}
 	}
 
-	if entry, exists := model.library.Load(dictOpts.Name, word); exists {
+	if entry, exists := model.library.Load(dictOpts.Name, model.normalize(word)); exists {
 		return &entry, nil
 	}
 	return nil, nil
@@ -321,7 +314,19 @@ func (model *SpellModel) RemoveEntry(word string, opts ...utils.DictionaryOption
 		}
 	}
 
-	return model.library.Remove(dictOpts.Name, word), nil
+	word = model.normalize(word)
+
+	if index, exists := model.phonetics.Load(dictOpts.Name); exists {
+		index.Remove(word)
+	}
+
+	if !model.library.Remove(dictOpts.Name, word) {
+		return false, nil
+	}
+
+	model.prefixes.Remove(dictOpts.Name, word)
+
+	return true, nil
 }
 
 // RemoveEntries bathc remove of entries
@@ -343,42 +348,51 @@ func (model *SpellModel) RemoveEntries(words []string, opts ...utils.DictionaryO
 
 
 // Save a representation of spell to disk at filename
-func (model *SpellModel) Save(filename string) error {
-	jsonStr, _ := json.Marshal(map[string]interface{}{
-		"options": map[string]interface{}{
-			"editDistance": model.MaxEditDistance,
-			"prefixLength": model.PrefixLength,
-		},
-		"words": model.library.Dictionaries,
-	})
-
+func (model *SpellModel) Save(filename string) (err error) {
 	f, err := os.Create(filename)
 	if err != nil {
 		return err
 	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
 
 	w := gzip.NewWriter(f)
-	_, err = w.Write(jsonStr)
-	if err != nil {
+	if _, err := model.WriteTo(w); err != nil {
 		return err
 	}
 
-	err = w.Close()
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return w.Close()
 }
 
 
 type lookupParams struct {
+	context          *lookupContext
+	costFunction     func([]rune, []rune, float64) float64
+	ctx              context.Context
+	deadline         time.Time
+	dictionaries     []DictionaryWeight
 	dictOpts         *utils.DictOptions
 	distanceFunction func([]rune, []rune, int) int
+	distancePolicy   func(int) uint32
 	editDistance     uint32
+	editScripts      bool
+	exhaustive       bool
+	filters          []func(utils.Entry) bool
+	maxResults       int
+	minSimilarity    float64
+	noisyChannel     bool
+	phonetic         bool
 	prefixLength     uint32
+	rules            *utils.RuleSet
+	scanFallback     bool
 	sortFunc         func(utils.SuggestionList)
 	suggestionLevel  suggestionLevel
+	timeBudget       time.Duration
+	truncated        *bool
+	subLookup        bool
 }
 
 func (model *SpellModel) defaultLookupParams() *lookupParams {
@@ -392,9 +406,9 @@ func (model *SpellModel) defaultLookupParams() *lookupParams {
 				s1 := results[i]
 				s2 := results[j]
 
-				if s1.Distance < s2.Distance {
+				if s1.Cost < s2.Cost {
 					return true
-				} else if s1.Distance == s2.Distance {
+				} else if s1.Cost == s2.Cost {
 					return s1.Frequency > s2.Frequency
 				}
 
@@ -405,6 +419,26 @@ func (model *SpellModel) defaultLookupParams() *lookupParams {
 	}
 }
 
+// withLookupOptions returns a copy of opts with extra appended, leaving the
+// backing array of opts untouched
+func withLookupOptions(opts []LookupOption, extra ...LookupOption) []LookupOption {
+	merged := make([]LookupOption, 0, len(opts)+len(extra))
+	merged = append(merged, opts...)
+	return append(merged, extra...)
+}
+
+func (model *SpellModel) newLookupParams(opts []LookupOption) (*lookupParams, error) {
+	lookupParams := model.defaultLookupParams()
+
+	for _, opt := range opts {
+		if err := opt(lookupParams); err != nil {
+			return nil, err
+		}
+	}
+
+	return lookupParams, nil
+}
+
 // LookupOption is a function that controls how a Lookup is performed. An error
 // will be returned if the LookupOption is invalid.
 type LookupOption func(*lookupParams) error
@@ -432,8 +466,24 @@ func DistanceFunc(df func([]rune, []rune, int) int) LookupOption {
 	}
 }
 
+// WeightedDistanceFunc accepts a function, f(str1, str2, maxCost), which
+// calculates a fractional cost between two strings, such as
+// utils.KeyboardDistance.WeightedRunes. It should return -1 if the cost is
+// greater than maxCost. The cost is used instead of the distance function to
+// find and rank suggestions, and the Distance of each suggestion is the cost
+// rounded up.
+func WeightedDistanceFunc(wf func([]rune, []rune, float64) float64) LookupOption {
+	return func(lp *lookupParams) error {
+		lp.costFunction = wf
+		return nil
+	}
+}
+
 // EditDistance allows the max edit distance to be set for the Lookup. Reducing
-// the edit distance will improve lookup performance.
+// the edit distance will improve lookup performance. An edit distance greater
+// than the MaxEditDistance the words were added with returns an error, unless
+// ScanFallback is enabled. Raising MaxEditDistance doesn't deepen the index of
+// the words already added.
 func EditDistance(dist uint32) LookupOption {
 	return func(lp *lookupParams) error {
 		lp.editDistance = dist
@@ -441,6 +491,64 @@ func EditDistance(dist uint32) LookupOption {
 	}
 }
 
+// ScanFallback defines whether a lookup with an edit distance greater than the
+// MaxEditDistance the words were added with compares the input with every
+// word, see LookupExhaustive, rather than return an error. The index of
+// deletes can't find words that far away, and scanning the dictionary is much
+// slower.
+func ScanFallback(enabled bool) LookupOption {
+	return func(lp *lookupParams) error {
+		lp.scanFallback = enabled
+		return nil
+	}
+}
+
+// EditDistancePolicy accepts a function, f(runeLen), which returns the edit
+// distance to look up an input of runeLen runes with, such as
+// DefaultEditDistancePolicy. It is applied to each word looked up, e.g. to each
+// part of the input during Segment, and takes precedence over EditDistance.
+// The distance is limited to the MaxEditDistance of the model and to the max
+// edit distance the words were added with.
+func EditDistancePolicy(policy func(int) uint32) LookupOption {
+	return func(lp *lookupParams) error {
+		lp.distancePolicy = policy
+		return nil
+	}
+}
+
+// DefaultEditDistancePolicy allows no edits for words of up to 2 runes, 1 edit
+// for words of up to 5 runes and 2 edits for longer words
+func DefaultEditDistancePolicy(runeLen int) uint32 {
+	switch {
+	case runeLen <= 2:
+		return 0
+	case runeLen <= 5:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// lookupEditDistance returns the edit distance to look up input with
+func (model *SpellModel) lookupEditDistance(input string, lookupParams *lookupParams) uint32 {
+	if lookupParams.distancePolicy == nil {
+		return lookupParams.editDistance
+	}
+
+	// The words may have been added with a smaller max edit distance than
+	// the current one, which limits how deep their deletes go
+	limit := model.MaxEditDistance
+	if depth := atomic.LoadUint32(&model.indexDepth); depth < limit {
+		limit = depth
+	}
+
+	dist := lookupParams.distancePolicy(len([]rune(input)))
+	if dist > limit {
+		dist = limit
+	}
+	return dist
+}
+
 // SortFunc allows the sorting of the SuggestionList to be configured. By
 // default, suggestions will be sorted by their edit distance, then their
 // frequency.
@@ -472,12 +580,229 @@ func PrefixLength(prefixLength uint32) LookupOption {
 	}
 }
 
+// EditScripts defines whether each suggestion should hold the edits that turn
+// the input into it, e.g. to highlight what was corrected. The edits are
+// between the input and the dictionary spelling of the suggestion, with
+// positions counted in runes.
+func EditScripts(include bool) LookupOption {
+	return func(lp *lookupParams) error {
+		lp.editScripts = include
+		return nil
+	}
+}
+
+// Filter accepts a function, f(entry), which reports whether a word may be
+// suggested. Words are filtered while candidates are generated, so BEST and
+// CLOSEST return the best words that pass the filter. When several filters are
+// set, words must pass all of them.
+func Filter(f func(utils.Entry) bool) LookupOption {
+	return func(lp *lookupParams) error {
+		lp.filters = append(lp.filters, f)
+		return nil
+	}
+}
+
+// FilterFields only suggests words whose WordData matches all the filters, see
+// Filter
+func FilterFields(filters ...utils.FieldFilter) LookupOption {
+	return Filter(func(e utils.Entry) bool {
+		for _, filter := range filters {
+			if !filter.Match(e) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// accepts reports whether an entry passes the filters
+func (lp *lookupParams) accepts(entry utils.Entry) bool {
+	for _, filter := range lp.filters {
+		if !filter(entry) {
+			return false
+		}
+	}
+	return true
+}
+
+// measure returns the distance and the cost between the input and a word other
+// than the input, or false if they are further apart than maxDist. Words at no
+// distance are left out too, since only the input itself is an exact match.
+// With a weighted distance the cost decides, and the distance is the cost
+// rounded up.
+func (lp *lookupParams) measure(input, word []rune, maxDist int) (int, float64, bool) {
+	if lp.costFunction == nil {
+		dist := lp.distanceFunction(input, word, maxDist)
+		return dist, float64(dist), dist > 0
+	}
+
+	cost := lp.costFunction(input, word, float64(maxDist))
+	if cost <= 0 {
+		return 0, 0, false
+	}
+	return int(math.Ceil(cost)), cost, true
+}
+
+// MaxResults limits the number of suggestions returned to the first n. As the
+// suggestions are found, the edit distance is narrowed to that of the n-th
+// closest one, so lookups with SuggestionLevel(ALL) finish sooner.
+func MaxResults(n int) LookupOption {
+	return func(lp *lookupParams) error {
+		if n < 1 {
+			return errors.New("max results must be greater than 0")
+		}
+		lp.maxResults = n
+		return nil
+	}
+}
+
+// MinSimilarity sets how similar suggestions must be to the input, as a ratio
+// between 0 and 1. The similarity of a suggestion is one minus its distance
+// divided by the length of the longer of the input and the suggestion, e.g.
+// "cat" and "car" are 0.67 similar while "internationalisation" and
+// "internationalization" are 0.95 similar.
+func MinSimilarity(ratio float64) LookupOption {
+	return func(lp *lookupParams) error {
+		if ratio < 0 || ratio > 1 {
+			return errors.New("min similarity must be between 0 and 1")
+		}
+		lp.minSimilarity = ratio
+		return nil
+	}
+}
+
+// similarDistance returns the largest distance between an input and a word of
+// the given lengths that keeps the minimum similarity
+func (lp *lookupParams) similarDistance(inputLen, wordLen int) int {
+	// Allow for rounding errors, e.g. (1 - 0.9) * 20 is just below 2
+	return int(math.Floor((1-lp.minSimilarity)*float64(utils.Max(inputLen, wordLen)) + 1e-9))
+}
+
+// limitDistance narrows the edit distance to the distance of the last of the
+// top results, and drops the results that are further
+func (lp *lookupParams) limitDistance(results utils.SuggestionList, editDistance int) (utils.SuggestionList, int) {
+	distances := make([]int, len(results))
+	for i, result := range results {
+		distances[i] = result.Distance
+	}
+	sort.Ints(distances)
+
+	if limit := distances[lp.maxResults-1]; limit < editDistance {
+		editDistance = limit
+		kept := results[:0]
+		for _, result := range results {
+			if result.Distance <= editDistance {
+				kept = append(kept, result)
+			}
+		}
+		results = kept
+	}
+
+	return results, editDistance
+}
+
+// limitResults keeps the sorted results the suggestion level and the maximum
+// number of results allow
+func (lp *lookupParams) limitResults(results utils.SuggestionList) utils.SuggestionList {
+	if len(results) == 0 {
+		return results
+	}
+
+	switch lp.suggestionLevel {
+	case BEST:
+		results = results[:1]
+	case CLOSEST:
+		closest := 1
+		for closest < len(results) && results[closest].Distance == results[0].Distance {
+			closest++
+		}
+		results = results[:closest]
+	}
+
+	if lp.maxResults > 0 && len(results) > lp.maxResults {
+		results = results[:lp.maxResults]
+	}
+
+	return results
+}
+
+// TimeBudget limits how long a lookup may take. When the budget runs out the
+// best suggestions found so far are returned, see Truncated to find out
+// whether that happened. The budget applies to each lookup, e.g. to each part
+// of the input during Segment.
+func TimeBudget(budget time.Duration) LookupOption {
+	return func(lp *lookupParams) error {
+		if budget <= 0 {
+			return errors.New("time budget must be greater than 0")
+		}
+		lp.timeBudget = budget
+		return nil
+	}
+}
+
+// Truncated sets truncated to whether the lookup ran out of its time budget
+// and returned partial results. For Segment, LookupCompound and CheckText it is
+// set if any of their lookups was truncated.
+func Truncated(truncated *bool) LookupOption {
+	return func(lp *lookupParams) error {
+		lp.truncated = truncated
+		return nil
+	}
+}
+
+// subLookup marks one of the many lookups of Segment, LookupCompound or
+// CheckText, which clear Truncated once so that a later lookup doesn't hide
+// that an earlier one was truncated
+func subLookup() LookupOption {
+	return func(lp *lookupParams) error {
+		lp.subLookup = true
+		return nil
+	}
+}
+
+// resetTruncated clears Truncated at the start of a public lookup
+func (lp *lookupParams) resetTruncated() {
+	if lp.truncated != nil {
+		*lp.truncated = false
+	}
+}
+
+// withContext sets the context a lookup can be cancelled with
+func withContext(ctx context.Context) LookupOption {
+	return func(lp *lookupParams) error {
+		lp.ctx = ctx
+		return nil
+	}
+}
+
+// interrupted reports whether a lookup must stop early, either because its
+// context is done, which is returned as an error, or because it ran out of
+// time, in which case it is truncated
+func (lp *lookupParams) interrupted() (bool, error) {
+	if lp.ctx != nil {
+		if err := lp.ctx.Err(); err != nil {
+			return true, err
+		}
+	}
+
+	if !lp.deadline.IsZero() && time.Now().After(lp.deadline) {
+		if lp.truncated != nil {
+			*lp.truncated = true
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
+
 func (model *SpellModel) newDictSuggestion(input string, dist int, dp *utils.DictOptions) utils.Suggestion {
 	entry, _ := model.library.Load(dp.Name, input)
 
 	return utils.Suggestion{
-		Distance: dist,
-		Entry:    entry,
+		Distance:   dist,
+		Cost:       float64(dist),
+		Dictionary: dp.Name,
+		Entry:      entry,
 	}
 }
 
@@ -488,19 +813,81 @@ func (model *SpellModel) newDictSuggestion(input string, dist int, dp *utils.Dic
 // Accepts zero or more LookupOption that can be used to configure how lookup
 // occurs.
 func (model *SpellModel) Lookup(input string, opts ...LookupOption) (utils.SuggestionList, error) {
-	lookupParams := model.defaultLookupParams()
+	lookupParams, err := model.newLookupParams(opts)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, opt := range opts {
-		if err := opt(lookupParams); err != nil {
-			return nil, err
+	if !lookupParams.subLookup {
+		lookupParams.resetTruncated()
+	}
+	if lookupParams.timeBudget > 0 {
+		lookupParams.deadline = time.Now().Add(lookupParams.timeBudget)
+	}
+	if _, err := lookupParams.interrupted(); err != nil {
+		return nil, err
+	}
+
+	key := model.normalize(input)
+	lookupParams.editDistance = model.lookupEditDistance(key, lookupParams)
+
+	// The deletes only go as deep as the max edit distance the words were
+	// added with, so words further away can only be found by comparing every
+	// word
+	depth := atomic.LoadUint32(&model.indexDepth)
+	if lookupParams.editDistance > depth && !lookupParams.exhaustive {
+		if !lookupParams.scanFallback {
+			return nil, fmt.Errorf("edit distance %d is greater than the max edit distance %d the words were added with",
+				lookupParams.editDistance, depth)
 		}
+		lookupParams.exhaustive = true
+	}
+
+	var results utils.SuggestionList
+	if len(lookupParams.dictionaries) > 0 {
+		results, err = model.lookupDictionaries(key, lookupParams)
+	} else {
+		results, err = model.lookupDictionary(key, lookupParams)
+	}
+
+	if err == nil && lookupParams.editScripts {
+		inputRunes := []rune(input)
+		for i := range results {
+			results[i].Edits = utils.DamerauLevenshteinEditsRunes(inputRunes, []rune(results[i].Word))
+		}
+	}
+
+	return results, err
+}
+
+// lookupDictionary looks up the input in the dictionary of the lookup params
+func (model *SpellModel) lookupDictionary(input string, lookupParams *lookupParams) (utils.SuggestionList, error) {
+	if lookupParams.context != nil || lookupParams.noisyChannel {
+		return model.lookupRanked(input, lookupParams)
+	}
+
+	return model.lookup(input, lookupParams)
+}
+
+// LookupContext is the same as Lookup but stops with the error of ctx once
+// ctx is done
+func (model *SpellModel) LookupContext(ctx context.Context, input string, opts ...LookupOption) (utils.SuggestionList, error) {
+	return model.Lookup(input, withLookupOptions(opts, withContext(ctx))...)
+}
+
+func (model *SpellModel) lookup(input string, lookupParams *lookupParams) (utils.SuggestionList, error) {
+	if lookupParams.rules != nil {
+		return model.lookupRules(input, lookupParams)
+	}
+	if lookupParams.exhaustive {
+		return model.lookupExhaustive(input, lookupParams)
 	}
 
 	results := utils.SuggestionList{}
 	dict := lookupParams.dictOpts.Name
 
 	// Check for an exact match
-	if _, exists := model.library.Load(dict, input); exists {
+	if entry, exists := model.library.Load(dict, input); exists && lookupParams.accepts(entry) {
 		results = append(results, model.newDictSuggestion(input, 0, lookupParams.dictOpts))
 
 		if lookupParams.suggestionLevel != ALL {
@@ -509,14 +896,21 @@ func (model *SpellModel) Lookup(input string, opts ...LookupOption) (utils.Sugge
 	}
 
 	editDistance := int(lookupParams.editDistance)
+	inputRunes := []rune(input)
+	inputLen := len(inputRunes)
+
+	// No word can be further than the minimum similarity allows for the
+	// longest word within the edit distance
+	if lookupParams.minSimilarity > 0 {
+		editDistance = utils.Min(editDistance,
+			lookupParams.similarDistance(inputLen, inputLen+editDistance))
+	}
 
 	// If edit distance is 0, just check if input is in the dictionary
 	if editDistance == 0 {
 		return results, nil
 	}
 
-	inputRunes := []rune(input)
-	inputLen := len(inputRunes)
 	prefixLength := int(lookupParams.prefixLength)
 
 	// Keep track of the deletes we've already considered
@@ -534,6 +928,13 @@ func (model *SpellModel) Lookup(input string, opts ...LookupOption) (utils.Sugge
 	candidates = append(candidates, utils.Substring(input, 0, inputPrefixLen))
 
 	for i := 0; i < len(candidates); i++ {
+		// Keep the suggestions found so far if the lookup runs out of time
+		if stop, err := lookupParams.interrupted(); err != nil {
+			return nil, err
+		} else if stop {
+			break
+		}
+
 		candidate := candidates[i]
 		candidateLen := len([]rune(candidate))
 		lengthDiff := inputPrefixLen - candidateLen
@@ -579,13 +980,20 @@ func (model *SpellModel) Lookup(input string, opts ...LookupOption) (utils.Sugge
 
 				var dist int
 
+				// The distance allowed for this suggestion
+				maxDist := editDistance
+				if lookupParams.minSimilarity > 0 {
+					maxDist = utils.Min(maxDist, lookupParams.similarDistance(inputLen, suggestionLen))
+				}
+
 				// If the candidate is an empty string and maps to a bin with
 				// suggestions (i.e. hash collision), ignore the suggestion if
 				// its edit distance with the input is greater than max edit
-				// distance
-				if candidateLen == 0 {
+				// distance. Runes they share past the prefix can make them
+				// closer, so truncated words are compared in full.
+				if candidateLen == 0 && inputLen <= prefixLength && suggestionLen <= prefixLength {
 					dist = utils.Max(inputLen, suggestionLen)
-					if dist > editDistance ||
+					if dist > maxDist ||
 						!utils.AddKey(consideredSuggestions, suggestion.Str) {
 						continue
 					}
@@ -601,7 +1009,7 @@ func (model *SpellModel) Lookup(input string, opts ...LookupOption) (utils.Sugge
 						dist = inputLen
 					}
 
-					if dist > editDistance ||
+					if dist > maxDist ||
 						!utils.AddKey(consideredSuggestions, suggestion.Str) {
 						continue
 					}
@@ -609,14 +1017,39 @@ func (model *SpellModel) Lookup(input string, opts ...LookupOption) (utils.Sugge
 					if !utils.AddKey(consideredSuggestions, suggestion.Str) {
 						continue
 					}
-					if dist = lookupParams.distanceFunction(inputRunes, suggestion.Runes, editDistance); dist < 1 {
+					if lookupParams.costFunction == nil {
+						var ok bool
+						if dist, _, ok = lookupParams.measure(inputRunes, suggestion.Runes, maxDist); !ok {
+							continue
+						}
+					}
+				}
+
+				// With a weighted distance the cost decides, and the distance
+				// is the cost rounded up
+				cost := float64(dist)
+				if lookupParams.costFunction != nil {
+					var ok bool
+					if dist, cost, ok = lookupParams.measure(inputRunes, suggestion.Runes, maxDist); !ok {
 						continue
 					}
 				}
 
 				// Determine whether or not this suggestion should be added to
 				// the results and if so, how.
-				if dist <= editDistance {
+				if dist <= maxDist {
+					// Skip words that were removed, as their deletes stay in
+					// the index
+					entry, exists := model.library.Load(dict, suggestion.Str)
+					if !exists {
+						continue
+					}
+
+					// Skip words that don't pass the filters
+					if !lookupParams.accepts(entry) {
+						continue
+					}
+
 					if len(results) > 0 {
 						switch lookupParams.suggestionLevel {
 						case CLOSEST:
@@ -624,14 +1057,14 @@ func (model *SpellModel) Lookup(input string, opts ...LookupOption) (utils.Sugge
 								results = utils.SuggestionList{}
 							}
 						case BEST:
-							entry, _ := model.library.Load(lookupParams.dictOpts.Name, suggestion.Str)
-
 							curFreq := entry.Frequency
 							closestFreq := results[0].Frequency
 
-							if dist < editDistance || curFreq > closestFreq {
+							if dist < editDistance || cost < results[0].Cost ||
+								(cost == results[0].Cost && curFreq > closestFreq) {
 								editDistance = dist
 								results[0] = model.newDictSuggestion(suggestion.Str, dist, lookupParams.dictOpts)
+								results[0].Cost = cost
 							}
 							continue
 						}
@@ -641,8 +1074,15 @@ func (model *SpellModel) Lookup(input string, opts ...LookupOption) (utils.Sugge
 						editDistance = dist
 					}
 
-					results = append(results,
-						model.newDictSuggestion(suggestion.Str, dist, lookupParams.dictOpts))
+					result := model.newDictSuggestion(suggestion.Str, dist, lookupParams.dictOpts)
+					result.Cost = cost
+					results = append(results, result)
+
+					// Once there are enough results, anything further than
+					// the last of them can't make it into the top
+					if lookupParams.maxResults > 0 && len(results) >= lookupParams.maxResults {
+						results, editDistance = lookupParams.limitDistance(results, editDistance)
+					}
 				}
 
 			}
@@ -665,9 +1105,17 @@ func (model *SpellModel) Lookup(input string, opts ...LookupOption) (utils.Sugge
 		}
 	}
 
+	if lookupParams.phonetic {
+		results = model.mergePhonetic(input, results, lookupParams)
+	}
+
 	// Order the results
 	lookupParams.sortFunc(results)
 
+	if lookupParams.maxResults > 0 && len(results) > lookupParams.maxResults {
+		results = results[:lookupParams.maxResults]
+	}
+
 	return results, nil
 }
 
@@ -730,6 +1178,12 @@ func (s SegmentResult) String() string {
 // Accepts zero or more SegmentOption that can be used to configure how
 // segmentation occurs
 func (model *SpellModel) Segment(input string, opts ...SegmentOption) (*SegmentResult, error) {
+	return model.SegmentContext(context.Background(), input, opts...)
+}
+
+// SegmentContext is the same as Segment but stops with the error of ctx once
+// ctx is done
+func (model *SpellModel) SegmentContext(ctx context.Context, input string, opts ...SegmentOption) (*SegmentResult, error) {
 	segmentParams := model.defaultSegmentParams()
 
 	for _, opt := range opts {
@@ -738,6 +1192,13 @@ func (model *SpellModel) Segment(input string, opts ...SegmentOption) (*SegmentR
 		}
 	}
 
+	lookupParams, err := model.newLookupParams(segmentParams.lookupOptions)
+	if err != nil {
+		return nil, err
+	}
+	lookupParams.resetTruncated()
+	dict := lookupParams.dictOpts.Name
+
 	longestWord := int(atomic.LoadUint32(&model.longestWord))
 	if longestWord == 0 {
 		return nil, errors.New("longest word in dictionary has zero length")
@@ -756,12 +1217,17 @@ func (model *SpellModel) Segment(input string, opts ...SegmentOption) (*SegmentR
 	type composition struct {
 		segmentedString string
 		correctedString string
+		lastWord        string
 		distanceSum     int
 		probability     float64
 	}
 	compositions := make([]composition, arraySize)
+	lookupOptions := withLookupOptions(segmentParams.lookupOptions, withContext(ctx), subLookup())
 
 	for i := 0; i < inputLen; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 
 		jMax := utils.Min(inputLen-i, longestWord)
 
@@ -783,7 +1249,7 @@ func (model *SpellModel) Segment(input string, opts ...SegmentOption) (*SegmentR
 			part = strings.Replace(part, " ", "", -1)
 			topEd -= len([]rune(part))
 
-			suggestions, err := model.Lookup(part, segmentParams.lookupOptions...)
+			suggestions, err := model.Lookup(part, lookupOptions...)
 			if err != nil {
 				return nil, err
 			}
@@ -808,10 +1274,20 @@ func (model *SpellModel) Segment(input string, opts ...SegmentOption) (*SegmentR
 				compositions[destinationIdx] = composition{
 					segmentedString: part,
 					correctedString: topResult,
+					lastWord:        topResult,
 					distanceSum:     topEd,
 					probability:     topProbabilityLog,
 				}
-			} else if j == longestWord ||
+				continue
+			}
+
+			// Prefer the probability of following the previous word when
+			// the pair is a known bigram
+			if p, exists := model.bigramProbability(dict, compositions[circularIdx].lastWord, topResult); exists {
+				topProbabilityLog = math.Log10(p)
+			}
+
+			if j == longestWord ||
 				((compositions[circularIdx].distanceSum+topEd ==
 					compositions[destinationIdx].distanceSum ||
 					compositions[circularIdx].distanceSum+separatorLength+topEd ==
@@ -822,6 +1298,7 @@ func (model *SpellModel) Segment(input string, opts ...SegmentOption) (*SegmentR
 				compositions[destinationIdx] = composition{
 					segmentedString: compositions[circularIdx].segmentedString + " " + part,
 					correctedString: compositions[circularIdx].correctedString + " " + topResult,
+					lastWord:        topResult,
 					distanceSum:     compositions[circularIdx].distanceSum + separatorLength + topEd,
 					probability:     compositions[circularIdx].probability + topProbabilityLog,
 				}
@@ -841,7 +1318,7 @@ func (model *SpellModel) Segment(input string, opts ...SegmentOption) (*SegmentR
 	segments := make([]Segment, len(correctedWords))
 
 	for i, word := range correctedWords {
-		e, err := model.GetEntry(word)
+		e, err := model.GetEntry(word, DictionaryName(dict))
 		if err != nil {
 			return nil, err
 		}
@@ -861,7 +1338,7 @@ func (model *SpellModel) Segment(input string, opts ...SegmentOption) (*SegmentR
 	return &result, nil
 }
 
-func (model *SpellModel) generateDeletes(word string, editDistance uint32, deletes deletes) deletes {
+func (model *SpellModel) generateDeletes(word string, editDistance, maxEditDistance uint32, deletes deletes) deletes {
 	editDistance++
 
 	if wordLen := len([]rune(word)); wordLen > 1 {
@@ -872,8 +1349,8 @@ func (model *SpellModel) generateDeletes(word string, editDistance uint32, delet
 			if _, exists := deletes[deleteHash]; !exists {
 				deletes[deleteHash] = struct{}{}
 
-				if editDistance < model.MaxEditDistance {
-					model.generateDeletes(deleteWord, editDistance, deletes)
+				if editDistance < maxEditDistance {
+					model.generateDeletes(deleteWord, editDistance, maxEditDistance, deletes)
 				}
 			}
 
@@ -887,6 +1364,8 @@ func (model *SpellModel) generateDeletes(word string, editDistance uint32, delet
 func (model *SpellModel) getDeletes(word string) deletes {
 	deletes := deletes{}
 	wordLen := len([]rune(word))
+	maxEditDistance := model.MaxEditDistance
+	model.recordIndexDepth(maxEditDistance)
 
 	// Restrict the size of the word to the max length of the prefix we'll
 	// examine
@@ -897,7 +1376,24 @@ func (model *SpellModel) getDeletes(word string) deletes {
 	wordHash := utils.GetStringHash(word)
 	deletes[wordHash] = struct{}{}
 
-	return model.generateDeletes(word, 0, deletes)
+	// Deletes stop at a single rune, so words, or prefixes, short enough to
+	// be deleted entirely need the empty delete as well
+	if utils.Min(wordLen, int(model.PrefixLength)) <= int(maxEditDistance) {
+		deletes[utils.GetStringHash("")] = struct{}{}
+	}
+
+	return model.generateDeletes(word, 0, maxEditDistance, deletes)
+}
+
+// recordIndexDepth lowers the index depth to the max edit distance the deletes
+// of a word are generated with
+func (model *SpellModel) recordIndexDepth(maxEditDistance uint32) {
+	for {
+		depth := atomic.LoadUint32(&model.indexDepth)
+		if maxEditDistance >= depth || atomic.CompareAndSwapUint32(&model.indexDepth, depth, maxEditDistance) {
+			return
+		}
+	}
 }
 
 
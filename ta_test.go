@@ -1,11 +1,14 @@
 package ta
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
+	"reflect"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/agusnavce/ta/utils"
 )
@@ -92,6 +95,49 @@ func ExampleSpellModel_Lookup_configureDistanceFunc() {
 	}))
 }
 
+func ExampleSpellModel_Lookup_configureUnrestrictedDistance() {
+	// Create a new speller
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{
+		Frequency: 1,
+		Word:      "abc",
+	})
+
+	// The default distance can't edit a transposed pair again, so "ca" is 3
+	// edits away from "abc" rather than 2
+	suggestions, _ := s.Lookup("ca", EditDistance(2))
+	fmt.Printf("Damerau Levenshtein: %v\n", suggestions)
+
+	suggestions, _ = s.Lookup("ca", EditDistance(2),
+		DistanceFunc(utils.UnrestrictedDamerauLevenshteinRunes))
+	fmt.Printf("Unrestricted Damerau Levenshtein: %v\n", suggestions)
+	// Output:
+	// Damerau Levenshtein: []
+	// Unrestricted Damerau Levenshtein: [abc]
+}
+
+func ExampleSpellModel_Lookup_configureWeightedDistanceFunc() {
+	// Create a new speller
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{
+		Frequency: 1,
+		Word:      "word",
+	})
+	_, _ = s.AddEntry(utils.Entry{
+		Frequency: 100,
+		Word:      "ward",
+	})
+
+	// Configure the Lookup to make typos between adjacent keys cheaper
+	layout, _ := utils.GetKeyboardLayout(utils.QWERTY)
+	kd := utils.NewKeyboardDistance(layout)
+
+	suggestions, _ := s.Lookup("wprd", WeightedDistanceFunc(kd.WeightedRunes))
+	fmt.Printf("Suggestions are: %v, cost %v\n", suggestions, suggestions[0].Cost)
+	// Output:
+	// Suggestions are: [word], cost 0.5
+}
+
 func ExampleSpellModel_Lookup_configureSortFunc() {
 	// Create a new speller
 	s := NewSpellModel()
@@ -298,3 +344,425 @@ func TestCornertaes(t *testing.T) {
 		t.Fatal(fmt.Sprintf("Expected ' ', got %s", suggestions[0].Word))
 	}
 }
+
+func TestLookup_weightedDistance(t *testing.T) {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Frequency: 1, Word: "word"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 100, Word: "ward"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "wordy"})
+
+	layout, _ := utils.GetKeyboardLayout(utils.QWERTY)
+	kd := utils.NewKeyboardDistance(layout)
+
+	// Without weights the more frequent word wins
+	suggestions, err := s.Lookup("wprd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions[0].Word != "ward" {
+		t.Fatal(fmt.Sprintf("Expected ward, got %s", suggestions[0].Word))
+	}
+
+	suggestions, err = s.Lookup("wprd", WeightedDistanceFunc(kd.WeightedRunes),
+		SuggestionLevel(ALL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[word, ward, wordy]" {
+		t.Fatal(fmt.Sprintf("Expected [word, ward, wordy], got %v", suggestions))
+	}
+	if suggestions[0].Distance != 1 || suggestions[0].Cost != 0.5 {
+		t.Fatal("Expected distance 1 and cost 0.5 for word")
+	}
+
+	// The integer form can be used as a plain distance function
+	suggestions, err = s.Lookup("wprd", DistanceFunc(kd.Runes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions[0].Distance != 1 {
+		t.Fatal("Expected distance 1")
+	}
+}
+
+func TestLookupContext(t *testing.T) {
+	s, err := newWithExample()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	suggestions, err := s.LookupContext(context.Background(), "exampel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[example]" {
+		t.Fatal(fmt.Sprintf("Expected [example], got %v", suggestions))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := s.LookupContext(ctx, "exampel"); err != context.Canceled {
+		t.Fatal(fmt.Sprintf("Expected context.Canceled, got %v", err))
+	}
+	if _, err := s.SegmentContext(ctx, "anexample"); err != context.Canceled {
+		t.Fatal(fmt.Sprintf("Expected context.Canceled, got %v", err))
+	}
+
+	ctx, cancel = context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	if _, err := s.LookupContext(ctx, "exampel"); err != context.DeadlineExceeded {
+		t.Fatal(fmt.Sprintf("Expected context.DeadlineExceeded, got %v", err))
+	}
+}
+
+func TestLookup_timeBudget(t *testing.T) {
+	s, err := newWithExample()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	truncated := true
+	suggestions, err := s.Lookup("exampel", TimeBudget(time.Minute), Truncated(&truncated))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if truncated || suggestions.String() != "[example]" {
+		t.Fatal(fmt.Sprintf("Expected [example] without truncation, got %v", suggestions))
+	}
+
+	// A budget that has run out before the candidates are examined yields
+	// partial results rather than an error
+	suggestions, err = s.Lookup("exampel", TimeBudget(time.Nanosecond), Truncated(&truncated),
+		SuggestionLevel(ALL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !truncated || len(suggestions) != 0 {
+		t.Fatal(fmt.Sprintf("Expected truncated empty results, got %v", suggestions))
+	}
+
+	if _, err := s.Lookup("exampel", TimeBudget(0)); err == nil {
+		t.Fatal("Expected an error for an empty time budget")
+	}
+}
+
+func ExampleMinSimilarity() {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Frequency: 1, Word: "car"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 1, Word: "internationalization"})
+
+	// Both words are one edit away, but only the longer one is similar enough
+	for _, input := range []string{"cat", "internationalisation"} {
+		suggestions, _ := s.Lookup(input, MinSimilarity(0.9))
+		fmt.Println(input, suggestions)
+	}
+	// Output:
+	// cat []
+	// internationalisation [internationalization]
+}
+
+func TestLookup_maxResults(t *testing.T) {
+	s := NewSpellModel()
+	for i, word := range []string{"cat", "car", "cab", "can", "cart", "scat", "at", "coat"} {
+		_, _ = s.AddEntry(utils.Entry{Frequency: uint64(10 + i), Word: word})
+	}
+
+	all, err := s.Lookup("cat", SuggestionLevel(ALL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for n := 1; n <= len(all)+1; n++ {
+		suggestions, err := s.Lookup("cat", SuggestionLevel(ALL), MaxResults(n))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := all
+		if n < len(all) {
+			want = all[:n]
+		}
+		if suggestions.String() != want.String() {
+			t.Fatal(fmt.Sprintf("MaxResults(%d): expected %v, got %v", n, want, suggestions))
+		}
+	}
+
+	suggestions, err := s.Lookup("cat", SuggestionLevel(CLOSEST), MaxResults(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[cat]" {
+		t.Fatal(fmt.Sprintf("Expected [cat], got %v", suggestions))
+	}
+
+	if _, err := s.Lookup("cat", MaxResults(0)); err == nil {
+		t.Fatal("Expected an error for no results")
+	}
+}
+
+func TestLookup_minSimilarity(t *testing.T) {
+	s := NewSpellModel()
+	for i, word := range []string{"internationalization", "carts", "cart", "car"} {
+		_, _ = s.AddEntry(utils.Entry{Frequency: uint64(i + 1), Word: word})
+	}
+
+	tests := []struct {
+		input    string
+		ratio    float64
+		expected string
+	}{
+		{"cat", 0, "[car, cart, carts]"},
+		{"cat", 0.62, "[car, cart]"},
+		{"cat", 0.7, "[cart]"},
+		{"cats", 0.7, "[carts]"},
+		{"internationalisation", 0.9, "[internationalization]"},
+		{"internationalisation", 1, "[]"},
+	}
+
+	for i, d := range tests {
+		suggestions, err := s.Lookup(d.input, SuggestionLevel(ALL), MinSimilarity(d.ratio))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if suggestions.String() != d.expected {
+			t.Errorf("Test[%d]: expected %s, got %v", i, d.expected, suggestions)
+		}
+	}
+
+	if _, err := s.Lookup("cat", MinSimilarity(1.5)); err == nil {
+		t.Fatal("Expected an error for a ratio greater than 1")
+	}
+}
+
+func TestLookup_editDistancePolicy(t *testing.T) {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "at"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "cat"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "internationalization"})
+
+	tests := []struct {
+		input    string
+		policy   func(int) uint32
+		expected string
+	}{
+		{"ax", nil, "[at]"},
+		{"ax", DefaultEditDistancePolicy, "[]"},
+		{"cst", DefaultEditDistancePolicy, "[cat]"},
+		{"cxx", DefaultEditDistancePolicy, "[]"},
+		{"intrenationalizaton", DefaultEditDistancePolicy, "[internationalization]"},
+		// The policy is limited by the distance of the delete index
+		{"inrenationalizaton", func(int) uint32 { return 5 }, "[]"},
+	}
+
+	for i, d := range tests {
+		var opts []LookupOption
+		if d.policy != nil {
+			opts = append(opts, EditDistancePolicy(d.policy))
+		}
+
+		suggestions, err := s.Lookup(d.input, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if suggestions.String() != d.expected {
+			t.Errorf("Test[%d]: expected %s, got %v", i, d.expected, suggestions)
+		}
+	}
+
+	result, err := s.Segment("ax cat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.String() != "at cat" {
+		t.Fatal(fmt.Sprintf("Expected at cat, got %v", result))
+	}
+
+	result, err = s.Segment("ax cat", SegmentLookupOpts(EditDistancePolicy(DefaultEditDistancePolicy)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.String() != "ax cat" {
+		t.Fatal(fmt.Sprintf("Expected ax cat, got %v", result))
+	}
+}
+
+func TestLookup_policyRaisedMaxEditDistance(t *testing.T) {
+	s := NewSpellModel()
+	s.MaxEditDistance = 1
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "example"})
+
+	// The policy is limited by the distance the words were added with
+	s.MaxEditDistance = 2
+	policy := EditDistancePolicy(DefaultEditDistancePolicy)
+
+	suggestions, err := s.Lookup("exampel", policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[example]" {
+		t.Fatalf("Expected [example], got %v", suggestions)
+	}
+
+	result, err := s.Segment("exampel", SegmentLookupOpts(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.String() != "example" {
+		t.Fatalf("Expected example, got %v", result)
+	}
+}
+
+func TestLookup_scanFallback(t *testing.T) {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "example"})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 5, Word: "sample"})
+
+	// The deletes of the model don't go deep enough for 3 edits
+	if _, err := s.Lookup("exmpxx", EditDistance(3)); err == nil {
+		t.Fatal("Expected an error for an edit distance greater than the max")
+	}
+
+	suggestions, err := s.Lookup("exmpxx", EditDistance(3), ScanFallback(true),
+		SuggestionLevel(ALL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[example]" || suggestions[0].Distance != 3 {
+		t.Fatalf("Expected [example] at distance 3, got %v", suggestions)
+	}
+
+	// Within the max edit distance the index is used as usual
+	suggestions, err = s.Lookup("exampel", EditDistance(2), ScanFallback(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[example]" {
+		t.Fatalf("Expected [example], got %v", suggestions)
+	}
+}
+
+func TestLookup_raisedMaxEditDistance(t *testing.T) {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "example"})
+
+	// The deletes of example only go 2 edits deep
+	s.MaxEditDistance = 3
+	if _, err := s.Lookup("exmpxx"); err == nil {
+		t.Fatal("Expected an error for an edit distance deeper than the index")
+	}
+
+	suggestions, err := s.Lookup("exmpxx", ScanFallback(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[example]" {
+		t.Fatalf("Expected [example], got %v", suggestions)
+	}
+
+	// Words added after the change are indexed deeper, but the words added
+	// before still limit the lookup
+	_, _ = s.AddEntry(utils.Entry{Frequency: 5, Word: "sample"})
+	if _, err := s.Lookup("smpxx"); err == nil {
+		t.Fatal("Expected an error for an edit distance deeper than the index")
+	}
+
+	// Rebuilding the index with a normalizer uses the new max edit distance
+	_ = s.SetNormalizer(&utils.Normalizer{CaseFold: true})
+	suggestions, err = s.Lookup("exmpxx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[example]" {
+		t.Fatalf("Expected [example], got %v", suggestions)
+	}
+}
+
+func ExampleFilterFields() {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Frequency: 100, Word: "bat", WordData: utils.WordData{"type": "noun"}})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "bag", WordData: utils.WordData{"type": "noun"}})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 50, Word: "bad", WordData: utils.WordData{"type": "adjective"}})
+
+	suggestions, _ := s.Lookup("bax", SuggestionLevel(CLOSEST),
+		FilterFields(utils.FieldFilter{Field: "type", In: []interface{}{"adjective", "verb"}}))
+	fmt.Println(suggestions)
+	// Output:
+	// [bad]
+}
+
+func TestLookup_filter(t *testing.T) {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Frequency: 100, Word: "bat", WordData: utils.WordData{"type": "noun"}})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 10, Word: "bag", WordData: utils.WordData{"type": "noun"}})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 50, Word: "bad", WordData: utils.WordData{"type": "adjective"}})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 5, Word: "bandit", WordData: utils.WordData{"type": "noun"}})
+
+	rare := Filter(func(e utils.Entry) bool { return e.Frequency < 60 })
+	nouns := FilterFields(utils.FieldFilter{Field: "type", Equals: "noun"})
+
+	tests := []struct {
+		input    string
+		opts     []LookupOption
+		expected string
+	}{
+		{"bax", nil, "[bat]"},
+		{"bax", []LookupOption{rare}, "[bad]"},
+		{"bax", []LookupOption{rare, nouns}, "[bag]"},
+		{"bax", []LookupOption{rare, nouns, SuggestionLevel(ALL)}, "[bag]"},
+		// The exact match is filtered too
+		{"bat", []LookupOption{rare, SuggestionLevel(CLOSEST)}, "[bad, bag]"},
+		{"badit", []LookupOption{nouns}, "[bandit]"},
+	}
+
+	for i, d := range tests {
+		suggestions, err := s.Lookup(d.input, d.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if suggestions.String() != d.expected {
+			t.Errorf("Test[%d]: expected %s, got %v", i, d.expected, suggestions)
+		}
+	}
+}
+
+func ExampleEditScripts() {
+	s := NewSpellModel()
+	_, _ = s.AddEntry(utils.Entry{Frequency: 1, Word: "receive"})
+
+	suggestions, _ := s.Lookup("recieved", EditScripts(true))
+	for _, edit := range suggestions[0].Edits {
+		fmt.Printf("%s %q at %d\n", edit.Kind, edit.From, edit.Position)
+	}
+	// Output:
+	// transpose "ie" at 3
+	// delete "d" at 7
+}
+
+func TestLookup_editScripts(t *testing.T) {
+	s := NewSpellModel()
+	_ = s.SetNormalizer(&utils.Normalizer{CaseFold: true})
+	_, _ = s.AddEntry(utils.Entry{Frequency: 1, Word: "café"})
+
+	suggestions, err := s.Lookup("cafe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions[0].Edits != nil {
+		t.Fatal("Expected no edits unless requested")
+	}
+
+	// Edits are between the input as given and the dictionary spelling
+	suggestions, err = s.Lookup("Cafe", EditScripts(true), SuggestionLevel(ALL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []utils.Edit{
+		{Kind: utils.Substitute, Position: 0, TargetPosition: 0, From: "C", To: "c"},
+		{Kind: utils.Substitute, Position: 3, TargetPosition: 3, From: "e", To: "é"},
+	}
+	if len(suggestions) != 1 || !reflect.DeepEqual(suggestions[0].Edits, want) {
+		t.Fatal(fmt.Sprintf("Expected edits %v, got %v", want, suggestions))
+	}
+}
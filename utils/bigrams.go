@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"sync"
+)
+
+// Bigrams is a collection of bigram counts for each dictionary. Counts are
+// keyed by the two words joined with a single space.
+type Bigrams struct {
+	sync.RWMutex
+	Dictionaries map[string]map[string]uint64
+}
+
+// NewBigrams creates a new bigram collection
+func NewBigrams() *Bigrams {
+	return &Bigrams{
+		Dictionaries: make(map[string]map[string]uint64),
+	}
+}
+
+// BigramKey returns the key used to store the bigram w1 w2
+func BigramKey(w1, w2 string) string {
+	return w1 + " " + w2
+}
+
+// Load returns the count of the bigram w1 w2 in a given dictionary
+func (b *Bigrams) Load(dict, w1, w2 string) (uint64, bool) {
+	b.RLock()
+	count, exists := b.Dictionaries[dict][BigramKey(w1, w2)]
+	b.RUnlock()
+	return count, exists
+}
+
+// Store sets the count of the bigram w1 w2 in a given dictionary
+func (b *Bigrams) Store(dict, w1, w2 string, count uint64) {
+	b.Lock()
+	if _, exists := b.Dictionaries[dict]; !exists {
+		b.Dictionaries[dict] = make(map[string]uint64)
+	}
+
+	b.Dictionaries[dict][BigramKey(w1, w2)] = count
+
+	b.Unlock()
+}
+
+// Has reports whether a given dictionary contains any bigram
+func (b *Bigrams) Has(dict string) bool {
+	b.RLock()
+	defer b.RUnlock()
+	return len(b.Dictionaries[dict]) > 0
+}
@@ -112,4 +112,116 @@ func DamerauLevenshteinRunesBuffer(r1, r2 []rune, maxDist int, x, y []int) int {
 	}
 
 	return current
-}
\ No newline at end of file
+}
+
+// EditKind is the kind of an edit operation
+type EditKind int
+
+// Kinds of edit operations
+const (
+	Insert EditKind = iota
+	Delete
+	Substitute
+	Transpose
+)
+
+// String returns the name of the edit kind
+func (k EditKind) String() string {
+	switch k {
+	case Insert:
+		return "insert"
+	case Delete:
+		return "delete"
+	case Substitute:
+		return "substitute"
+	case Transpose:
+		return "transpose"
+	}
+	return "unknown"
+}
+
+// Edit is an operation of an edit script. Position is the rune position in
+// the source the operation applies to, and TargetPosition the rune position in
+// the target. From holds the runes removed from the source and To the runes
+// that replace them, e.g. a transposition of "ab" has From "ab" and To "ba".
+type Edit struct {
+	Kind           EditKind
+	Position       int
+	TargetPosition int
+	From           string
+	To             string
+}
+
+// DamerauLevenshteinEdits takes two strings and returns the edits of the
+// shortest edit script that transforms one string to another
+func DamerauLevenshteinEdits(str1, str2 string) []Edit {
+	return DamerauLevenshteinEditsRunes([]rune(str1), []rune(str2))
+}
+
+// DamerauLevenshteinEditsRunes is the same as DamerauLevenshteinEdits but
+// accepts runes instead of strings
+func DamerauLevenshteinEditsRunes(r1, r2 []rune) []Edit {
+	r1Len := len(r1)
+	r2Len := len(r2)
+
+	d := make([][]int, r1Len+1)
+	for i := range d {
+		d[i] = make([]int, r2Len+1)
+		d[i][0] = i
+	}
+	for j := 0; j <= r2Len; j++ {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= r1Len; i++ {
+		for j := 1; j <= r2Len; j++ {
+			cost := 1
+			if r1[i-1] == r2[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = Min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && r1[i-1] == r2[j-2] && r1[i-2] == r2[j-1] {
+				d[i][j] = Min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	edits := make([]Edit, 0, d[r1Len][r2Len])
+	i, j := r1Len, r2Len
+
+	// Walk back from the end, preferring matches and substitutions
+	for i > 0 || j > 0 {
+		switch {
+		case i > 0 && j > 0 && r1[i-1] == r2[j-1] && d[i][j] == d[i-1][j-1]:
+			i--
+			j--
+		case i > 0 && j > 0 && d[i][j] == d[i-1][j-1]+1:
+			i--
+			j--
+			edits = append(edits, Edit{Kind: Substitute, Position: i, TargetPosition: j,
+				From: string(r1[i]), To: string(r2[j])})
+		case i > 1 && j > 1 && r1[i-1] == r2[j-2] && r1[i-2] == r2[j-1] &&
+			d[i][j] == d[i-2][j-2]+1:
+			i -= 2
+			j -= 2
+			edits = append(edits, Edit{Kind: Transpose, Position: i, TargetPosition: j,
+				From: string(r1[i : i+2]), To: string(r2[j : j+2])})
+		case i > 0 && d[i][j] == d[i-1][j]+1:
+			i--
+			edits = append(edits, Edit{Kind: Delete, Position: i, TargetPosition: j,
+				From: string(r1[i])})
+		default:
+			j--
+			edits = append(edits, Edit{Kind: Insert, Position: i, TargetPosition: j,
+				To: string(r2[j])})
+		}
+	}
+
+	// The edits were collected from the end
+	for l, r := 0, len(edits)-1; l < r; l, r = l+1, r-1 {
+		edits[l], edits[r] = edits[r], edits[l]
+	}
+
+	return edits
+}
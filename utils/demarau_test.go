@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -57,6 +58,35 @@ func TestDamerauLevenshtein(t *testing.T) {
 	}
 }
 
+func TestDamerauLevenshteinEdits(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want []Edit
+	}{
+		{"cat", "cat", []Edit{}},
+		{"cat", "cst", []Edit{{Substitute, 1, 1, "a", "s"}}},
+		{"the", "teh", []Edit{{Transpose, 1, 1, "he", "eh"}}},
+		{"cart", "cat", []Edit{{Delete, 2, 2, "r", ""}}},
+		{"cat", "cart", []Edit{{Insert, 2, 2, "", "r"}}},
+		{"", "ab", []Edit{{Insert, 0, 0, "", "a"}, {Insert, 0, 1, "", "b"}}},
+		{"Kätzchen", "Katzchen", []Edit{{Substitute, 1, 1, "ä", "a"}}},
+		{"acress", "across", []Edit{{Substitute, 3, 3, "e", "o"}}},
+		{"recieve", "receive!", []Edit{{Transpose, 3, 3, "ie", "ei"}, {Insert, 7, 7, "", "!"}}},
+	}
+
+	for i, d := range tests {
+		edits := DamerauLevenshteinEdits(d.a, d.b)
+		if !reflect.DeepEqual(edits, d.want) {
+			t.Errorf("Test[%d]: DamerauLevenshteinEdits(%q,%q) returned %v, want %v",
+				i, d.a, d.b, edits, d.want)
+		}
+
+		if n := DamerauLevenshtein(d.a, d.b, 10); n != len(edits) {
+			t.Errorf("Test[%d]: got %d edits for distance %d", i, len(edits), n)
+		}
+	}
+}
+
 func BenchmarkDamerauLevenshtein(b *testing.B) {
 	tests := []struct {
 		a, b    string
@@ -0,0 +1,174 @@
+package utils
+
+import (
+	"sync"
+)
+
+// Default probability that a word is typed without any error
+const defaultNoErrorProbability = 0.95
+
+// wordBoundary stands for the start of a word in the confusion matrices, so
+// that edits on the first character have a preceding character
+const wordBoundary = '^'
+
+// ErrorModel is a noisy channel model of typing errors. It counts how often
+// each edit was made in a set of misspellings, following Kernighan, Church and
+// Gale. Keys are two characters:
+//   - Deletions["xy"]: "xy" typed as "x"
+//   - Insertions["xy"]: "x" typed as "xy"
+//   - Substitutions["xy"]: "y" typed as "x"
+//   - Transpositions["xy"]: "xy" typed as "yx"
+//
+// Unigrams and Bigrams count the characters of the corrections, which are
+// used to normalise the counts of the edits.
+type ErrorModel struct {
+	sync.RWMutex
+	Deletions          map[string]uint64
+	Insertions         map[string]uint64
+	Substitutions      map[string]uint64
+	Transpositions     map[string]uint64
+	Unigrams           map[string]uint64
+	Bigrams            map[string]uint64
+	Pairs              uint64
+	NoErrorProbability float64
+}
+
+// NewErrorModel creates a new, untrained error model
+func NewErrorModel() *ErrorModel {
+	return &ErrorModel{
+		Deletions:          make(map[string]uint64),
+		Insertions:         make(map[string]uint64),
+		Substitutions:      make(map[string]uint64),
+		Transpositions:     make(map[string]uint64),
+		Unigrams:           make(map[string]uint64),
+		Bigrams:            make(map[string]uint64),
+		NoErrorProbability: defaultNoErrorProbability,
+	}
+}
+
+// Add trains the model with a misspelling and its correction
+func (em *ErrorModel) Add(misspelling, correction string) {
+	typo := []rune(misspelling)
+	word := []rune(correction)
+
+	em.Lock()
+	defer em.Unlock()
+
+	em.Pairs++
+
+	prev := rune(wordBoundary)
+	em.Unigrams[string(prev)]++
+	for _, r := range word {
+		em.Unigrams[string(r)]++
+		em.Bigrams[string([]rune{prev, r})]++
+		prev = r
+	}
+
+	for _, edit := range DamerauLevenshteinEditsRunes(word, typo) {
+		switch edit.Kind {
+		case Delete:
+			em.Deletions[string([]rune{runeBefore(word, edit.Position), word[edit.Position]})]++
+		case Insert:
+			em.Insertions[string([]rune{runeBefore(word, edit.Position), typo[edit.TargetPosition]})]++
+		case Substitute:
+			em.Substitutions[string([]rune{typo[edit.TargetPosition], word[edit.Position]})]++
+		case Transpose:
+			em.Transpositions[string(word[edit.Position:edit.Position+2])]++
+		}
+	}
+}
+
+// Trained reports whether the model has been trained with any pair
+func (em *ErrorModel) Trained() bool {
+	em.RLock()
+	defer em.RUnlock()
+	return em.Pairs > 0
+}
+
+// Probability returns the probability of typing typo when meaning word. Edit
+// counts are add-one smoothed, so edits never seen in training are unlikely
+// but possible.
+func (em *ErrorModel) Probability(typo, word string) float64 {
+	return em.ProbabilityRunes([]rune(typo), []rune(word))
+}
+
+// ProbabilityRunes is the same as Probability but accepts runes instead of
+// strings
+func (em *ErrorModel) ProbabilityRunes(typo, word []rune) float64 {
+	em.RLock()
+	defer em.RUnlock()
+
+	if CompareSlices(typo, word) {
+		return em.NoErrorProbability
+	}
+
+	// The number of distinct characters, which smoothing spreads over
+	vocabulary := float64(Max(len(em.Unigrams), 1))
+
+	p := 1.0
+	for _, edit := range DamerauLevenshteinEditsRunes(word, typo) {
+		var count, total uint64
+
+		switch edit.Kind {
+		case Delete:
+			key := string([]rune{runeBefore(word, edit.Position), word[edit.Position]})
+			count, total = em.Deletions[key], em.Bigrams[key]
+		case Insert:
+			prev := runeBefore(word, edit.Position)
+			count = em.Insertions[string([]rune{prev, typo[edit.TargetPosition]})]
+			total = em.Unigrams[string(prev)]
+		case Substitute:
+			count = em.Substitutions[string([]rune{typo[edit.TargetPosition], word[edit.Position]})]
+			total = em.Unigrams[string(word[edit.Position])]
+		case Transpose:
+			key := string(word[edit.Position : edit.Position+2])
+			count, total = em.Transpositions[key], em.Bigrams[key]
+		}
+
+		p *= (float64(count) + 1) / (float64(total) + vocabulary)
+	}
+
+	return p * (1 - em.NoErrorProbability)
+}
+
+// runeBefore returns the character before position i of word, or the word
+// boundary at the start
+func runeBefore(word []rune, i int) rune {
+	if i == 0 {
+		return wordBoundary
+	}
+	return word[i-1]
+}
+
+// Rekey replaces every character of the keys of the model with what normalize
+// returns for it, adding together the counts of keys that end up the same. A
+// character that doesn't normalize to a single character is kept, since its
+// counts can't be split.
+func (em *ErrorModel) Rekey(normalize func(string) string) {
+	em.Lock()
+	defer em.Unlock()
+
+	rekey := func(counts map[string]uint64) map[string]uint64 {
+		rekeyed := make(map[string]uint64, len(counts))
+		for key, count := range counts {
+			chars := []rune(key)
+			for i, r := range chars {
+				if r == wordBoundary {
+					continue
+				}
+				if normalized := []rune(normalize(string(r))); len(normalized) == 1 {
+					chars[i] = normalized[0]
+				}
+			}
+			rekeyed[string(chars)] += count
+		}
+		return rekeyed
+	}
+
+	em.Deletions = rekey(em.Deletions)
+	em.Insertions = rekey(em.Insertions)
+	em.Substitutions = rekey(em.Substitutions)
+	em.Transpositions = rekey(em.Transpositions)
+	em.Unigrams = rekey(em.Unigrams)
+	em.Bigrams = rekey(em.Bigrams)
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestErrorModel(t *testing.T) {
+	em := NewErrorModel()
+	if em.Trained() {
+		t.Fatal("New error model should not be trained")
+	}
+
+	em.Add("teh", "the")
+	em.Add("hte", "the")
+	em.Add("bst", "bat")
+	em.Add("hst", "hat")
+
+	if !em.Trained() {
+		t.Fatal("Error model should be trained")
+	}
+	if em.Transpositions["he"] != 1 || em.Transpositions["th"] != 1 {
+		t.Fatalf("Unexpected transpositions %v", em.Transpositions)
+	}
+	if em.Substitutions["sa"] != 2 {
+		t.Fatalf("Unexpected substitutions %v", em.Substitutions)
+	}
+
+	if p := em.Probability("the", "the"); p != em.NoErrorProbability {
+		t.Fatalf("Expected probability %v for no error, got %v", em.NoErrorProbability, p)
+	}
+
+	// A substitution seen in training is more likely than an unseen one
+	if seen, unseen := em.Probability("cst", "cat"), em.Probability("cut", "cat"); seen <= unseen {
+		t.Fatalf("Expected %v to be greater than %v", seen, unseen)
+	}
+
+	// Every edit makes the typo less likely
+	if one, two := em.Probability("cst", "cat"), em.Probability("cstt", "cat"); two >= one {
+		t.Fatalf("Expected %v to be less than %v", two, one)
+	}
+}
+
+func TestErrorModel_Rekey(t *testing.T) {
+	em := NewErrorModel()
+	em.Add("Teh", "The")
+	em.Add("teh", "the")
+
+	em.Rekey((&Normalizer{CaseFold: true}).Normalize)
+
+	if em.Transpositions["he"] != 2 {
+		t.Fatalf("Expected the transpositions to be added together, got %v", em.Transpositions)
+	}
+	if em.Bigrams["^t"] != 2 || em.Unigrams["t"] != 2 {
+		t.Fatalf("Unexpected bigrams %v and unigrams %v", em.Bigrams, em.Unigrams)
+	}
+}
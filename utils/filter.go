@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"reflect"
+)
+
+// FieldFilter matches entries by a field of their WordData. An entry matches
+// if the field equals Equals or, when In is set, any of the values in In. If
+// neither is set, an entry matches if it has the field. Numbers are compared
+// by value, so 1 equals 1.0.
+type FieldFilter struct {
+	Field  string
+	Equals interface{}   `json:",omitempty"`
+	In     []interface{} `json:",omitempty"`
+}
+
+// Match reports whether the entry matches the filter
+func (ff FieldFilter) Match(e Entry) bool {
+	value, exists := e.WordData[ff.Field]
+	if !exists {
+		return false
+	}
+
+	if ff.In != nil {
+		for _, v := range ff.In {
+			if valuesEqual(value, v) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ff.Equals != nil {
+		return valuesEqual(value, ff.Equals)
+	}
+
+	return true
+}
+
+// valuesEqual compares two values of WordData, which may have been decoded as
+// a different numeric type than they were added with
+func valuesEqual(v1, v2 interface{}) bool {
+	if f1, ok := toFloat(v1); ok {
+		f2, ok := toFloat(v2)
+		return ok && f1 == f2
+	}
+
+	return reflect.DeepEqual(v1, v2)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
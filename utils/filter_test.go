@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestFieldFilter(t *testing.T) {
+	entry := Entry{
+		Word:     "run",
+		WordData: WordData{"type": "verb", "syllables": float64(1)},
+	}
+
+	tests := []struct {
+		filter FieldFilter
+		want   bool
+	}{
+		{FieldFilter{Field: "type"}, true},
+		{FieldFilter{Field: "plural"}, false},
+		{FieldFilter{Field: "type", Equals: "verb"}, true},
+		{FieldFilter{Field: "type", Equals: "noun"}, false},
+		{FieldFilter{Field: "type", In: []interface{}{"noun", "verb"}}, true},
+		{FieldFilter{Field: "type", In: []interface{}{"noun"}}, false},
+		{FieldFilter{Field: "type", In: []interface{}{}}, false},
+		{FieldFilter{Field: "syllables", Equals: 1}, true},
+		{FieldFilter{Field: "syllables", Equals: uint8(2)}, false},
+		{FieldFilter{Field: "syllables", Equals: "1"}, false},
+	}
+
+	for i, d := range tests {
+		if got := d.filter.Match(entry); got != d.want {
+			t.Errorf("Test[%d]: %+v returned %v, want %v", i, d.filter, got, d.want)
+		}
+	}
+}
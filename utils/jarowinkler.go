@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"math"
+)
+
+const (
+	// jaroWinklerThreshold is the Jaro similarity above which the common
+	// prefix is rewarded
+	jaroWinklerThreshold = 0.7
+	// jaroWinklerScaling is how much each rune of the common prefix adds
+	jaroWinklerScaling = 0.1
+	// jaroWinklerPrefix is the longest common prefix that is rewarded
+	jaroWinklerPrefix = 4
+)
+
+// JaroWinklerSimilarity takes two strings and returns their Jaro-Winkler
+// similarity, from 0 for strings with nothing in common to 1 for equal
+// strings
+func JaroWinklerSimilarity(str1, str2 string) float64 {
+	return JaroWinklerSimilarityRunes([]rune(str1), []rune(str2))
+}
+
+// JaroWinklerSimilarityRunes is the same as JaroWinklerSimilarity but accepts
+// runes instead of strings
+func JaroWinklerSimilarityRunes(r1, r2 []rune) float64 {
+	return JaroWinklerSimilarityRunesBuffer(r1, r2, nil, nil)
+}
+
+// JaroWinklerSimilarityRunesBuffer is the same as JaroWinklerSimilarityRunes
+// but also accepts memory buffers x and y which should be of size len(r1) and
+// len(r2)
+func JaroWinklerSimilarityRunesBuffer(r1, r2 []rune, x, y []bool) float64 {
+	if CompareSlices(r1, r2) {
+		return 1
+	}
+
+	r1Len := len(r1)
+	r2Len := len(r2)
+
+	if r1Len == 0 || r2Len == 0 {
+		return 0
+	}
+
+	if len(x) < r1Len {
+		x = make([]bool, r1Len)
+	}
+	if len(y) < r2Len {
+		y = make([]bool, r2Len)
+	}
+	x = x[:r1Len]
+	y = y[:r2Len]
+	for i := range x {
+		x[i] = false
+	}
+	for j := range y {
+		y[j] = false
+	}
+
+	// Runes match if they are equal and not further apart than the window
+	window := Max(Max(r1Len, r2Len)/2-1, 0)
+
+	matches := 0
+	for i := 0; i < r1Len; i++ {
+		start := Max(i-window, 0)
+		end := Min(i+window+1, r2Len)
+
+		for j := start; j < end; j++ {
+			if !y[j] && r1[i] == r2[j] {
+				x[i] = true
+				y[j] = true
+				matches++
+				break
+			}
+		}
+	}
+
+	if matches == 0 {
+		return 0
+	}
+
+	// Count the matching runes that are out of order
+	transpositions := 0
+	j := 0
+	for i := 0; i < r1Len; i++ {
+		if !x[i] {
+			continue
+		}
+		for !y[j] {
+			j++
+		}
+		if r1[i] != r2[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(r1Len) + m/float64(r2Len) + (m-float64(transpositions)/2)/m) / 3
+
+	if jaro <= jaroWinklerThreshold {
+		return jaro
+	}
+
+	prefix := 0
+	for prefix < Min(jaroWinklerPrefix, r1Len, r2Len) && r1[prefix] == r2[prefix] {
+		prefix++
+	}
+
+	return jaro + float64(prefix)*jaroWinklerScaling*(1-jaro)
+}
+
+// JaroWinkler adapts the Jaro-Winkler similarity of two strings to the
+// contract of the edit distance functions, so it can be used with
+// DistanceFunc. The distance is the dissimilarity times the length of the
+// longer string, rounded up, or -1 if it is greater than the maximum distance.
+func JaroWinkler(str1, str2 string, maxDist int) int {
+	return JaroWinklerRunes([]rune(str1), []rune(str2), maxDist)
+}
+
+// JaroWinklerRunes is the same as JaroWinkler but accepts runes instead of
+// strings
+func JaroWinklerRunes(r1, r2 []rune, maxDist int) int {
+	return JaroWinklerRunesBuffer(r1, r2, maxDist, nil, nil)
+}
+
+// JaroWinklerRunesBuffer is the same as JaroWinklerRunes but also accepts
+// memory buffers x and y which should be of size len(r1) and len(r2)
+func JaroWinklerRunesBuffer(r1, r2 []rune, maxDist int, x, y []bool) int {
+	similarity := JaroWinklerSimilarityRunesBuffer(r1, r2, x, y)
+
+	// Allow for rounding errors so that the distance is a whole number of
+	// runes when it should be
+	dist := int(math.Ceil((1-similarity)*float64(Max(len(r1), len(r2))) - 1e-9))
+	if dist > maxDist {
+		return -1
+	}
+
+	return dist
+}
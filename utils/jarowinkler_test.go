@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestJaroWinklerSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"", "testing", 0},
+		{"testing", "", 0},
+		{"testing", "testing", 1},
+		{"abc", "xyz", 0},
+		{"MARTHA", "MARHTA", 0.9611},
+		{"DWAYNE", "DUANE", 0.84},
+		{"DIXON", "DICKSONX", 0.8133},
+		{"CRATE", "TRACE", 0.7333},
+		{"Kätzchen", "Katzchen", 0.925},
+	}
+	for i, d := range tests {
+		s := JaroWinklerSimilarity(d.a, d.b)
+		if math.Abs(s-d.want) > 1e-4 {
+			t.Errorf("Test[%d]: JaroWinklerSimilarity(%q,%q) returned %v, want %v",
+				i, d.a, d.b, s, d.want)
+		}
+
+		r1 := []rune(d.a)
+		r2 := []rune(d.b)
+
+		s2 := JaroWinklerSimilarityRunes(r1, r2)
+		if s != s2 {
+			t.Error("JaroWinklerSimilarity() is not equal to JaroWinklerSimilarityRunes()")
+		}
+
+		x := make([]bool, len(r1))
+		y := make([]bool, len(r2))
+		s3 := JaroWinklerSimilarityRunesBuffer(r1, r2, x, y)
+		if s != s3 {
+			t.Error("JaroWinklerSimilarity() is not equal to JaroWinklerSimilarityRunesBuffer()")
+		}
+	}
+}
+
+func TestJaroWinkler(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		maxDist int
+		want    int
+	}{
+		{"", "", 10, 0},
+		{"", "testing", 10, 7},
+		{"testing", "testing", 10, 0},
+		{"abc", "xyz", 10, 3},
+		{"abc", "xyz", 2, -1},
+		{"MARTHA", "MARHTA", 10, 1},
+		{"DWAYNE", "DUANE", 10, 1},
+		{"DIXON", "DICKSONX", 10, 2},
+		{"DIXON", "DICKSONX", 1, -1},
+	}
+	for i, d := range tests {
+		n := JaroWinkler(d.a, d.b, d.maxDist)
+		if n != d.want {
+			t.Errorf("Test[%d]: JaroWinkler(%q,%q,%v) returned %v, want %v",
+				i, d.a, d.b, d.maxDist, n, d.want)
+		}
+
+		r1 := []rune(d.a)
+		r2 := []rune(d.b)
+
+		n2 := JaroWinklerRunes(r1, r2, d.maxDist)
+		if n != n2 {
+			t.Error("JaroWinkler() is not equal to JaroWinklerRunes()")
+		}
+
+		x := make([]bool, len(r1))
+		y := make([]bool, len(r2))
+		n3 := JaroWinklerRunesBuffer(r1, r2, d.maxDist, x, y)
+		if n != n3 {
+			t.Error("JaroWinkler() is not equal to JaroWinklerRunesBuffer()")
+		}
+	}
+}
+
+func BenchmarkJaroWinkler(b *testing.B) {
+	tests := []struct {
+		a, b    string
+		maxDist int
+		name    string
+	}{
+		{"levenshtein", "frankenstein", 10, "ASCII"},
+		{"Kätzchen", "Katzchen", 10, "UTF8"},
+	}
+	for _, test := range tests {
+		b.Run(test.name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				JaroWinkler(test.a, test.b, test.maxDist)
+			}
+		})
+		b.Run(test.name+"Runes", func(b *testing.B) {
+			r1 := []rune(test.a)
+			r2 := []rune(test.b)
+			for n := 0; n < b.N; n++ {
+				JaroWinklerRunes(r1, r2, test.maxDist)
+			}
+		})
+		b.Run(test.name+"RunesBuffer", func(b *testing.B) {
+			r1 := []rune(test.a)
+			r2 := []rune(test.b)
+			x := make([]bool, len(r1))
+			y := make([]bool, len(r2))
+			for n := 0; n < b.N; n++ {
+				JaroWinklerRunesBuffer(r1, r2, test.maxDist, x, y)
+			}
+		})
+	}
+}
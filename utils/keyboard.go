@@ -0,0 +1,228 @@
+package utils
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"unicode"
+)
+
+// Built-in keyboard layouts
+const (
+	QWERTY = "qwerty"
+	AZERTY = "azerty"
+	DVORAK = "dvorak"
+)
+
+// Default cost of substituting a key with one of its neighbours
+const defaultAdjacentCost = 0.5
+
+type keyPosition struct {
+	x, y float64
+}
+
+// KeyboardLayout describes where the keys of a keyboard are. Each row holds
+// the unshifted characters of a row of keys, and the offset of each row is
+// how far, in keys, its first key is shifted to the right of the first key of
+// the top row.
+type KeyboardLayout struct {
+	Name      string
+	Rows      []string
+	Offsets   []float64
+	positions map[rune]keyPosition
+}
+
+var (
+	layoutsMu sync.RWMutex
+	layouts   = map[string]*KeyboardLayout{}
+)
+
+func init() {
+	for _, layout := range []struct {
+		name    string
+		rows    []string
+		offsets []float64
+	}{
+		{QWERTY, []string{"1234567890-=", "qwertyuiop[]", "asdfghjkl;'", "zxcvbnm,./"}, []float64{0, 0.5, 0.75, 1.25}},
+		{AZERTY, []string{"&é\"'(-è_çà)=", "azertyuiop^$", "qsdfghjklmù*", "<wxcvbn,;:!"}, []float64{0, 0.5, 0.75, 0.25}},
+		{DVORAK, []string{"1234567890[]", "',.pyfgcrl/=", "aoeuidhtns-", ";qjkxbmwvz"}, []float64{0, 0.5, 0.75, 1.25}},
+	} {
+		l, _ := NewKeyboardLayout(layout.name, layout.rows, layout.offsets)
+		RegisterKeyboardLayout(l)
+	}
+}
+
+// NewKeyboardLayout creates a new keyboard layout. Rows and offsets must have
+// the same length.
+func NewKeyboardLayout(name string, rows []string, offsets []float64) (*KeyboardLayout, error) {
+	if len(rows) != len(offsets) {
+		return nil, errors.New("keyboard layout needs one offset per row")
+	}
+
+	l := &KeyboardLayout{
+		Name:      name,
+		Rows:      rows,
+		Offsets:   offsets,
+		positions: make(map[rune]keyPosition),
+	}
+
+	for y, row := range rows {
+		x := 0
+		for _, r := range row {
+			l.positions[unicode.ToLower(r)] = keyPosition{
+				x: offsets[y] + float64(x),
+				y: float64(y),
+			}
+			x++
+		}
+	}
+
+	return l, nil
+}
+
+// RegisterKeyboardLayout makes a layout available through GetKeyboardLayout,
+// replacing any layout registered with the same name
+func RegisterKeyboardLayout(layout *KeyboardLayout) {
+	layoutsMu.Lock()
+	layouts[layout.Name] = layout
+	layoutsMu.Unlock()
+}
+
+// GetKeyboardLayout returns the layout registered with name
+func GetKeyboardLayout(name string) (*KeyboardLayout, bool) {
+	layoutsMu.RLock()
+	layout, exists := layouts[name]
+	layoutsMu.RUnlock()
+	return layout, exists
+}
+
+// Adjacent reports whether r1 and r2 are typed with the same key or with keys
+// that touch each other
+func (l *KeyboardLayout) Adjacent(r1, r2 rune) bool {
+	p1, exists1 := l.positions[unicode.ToLower(r1)]
+	p2, exists2 := l.positions[unicode.ToLower(r2)]
+	if !exists1 || !exists2 {
+		return false
+	}
+
+	return math.Abs(p1.y-p2.y) <= 1 && math.Abs(p1.x-p2.x) <= 1
+}
+
+// KeyboardDistance is a Damerau-Levenshtein distance where substituting a key
+// with a neighbouring key costs AdjacentCost instead of 1
+type KeyboardDistance struct {
+	Layout       *KeyboardLayout
+	AdjacentCost float64
+}
+
+// NewKeyboardDistance creates a KeyboardDistance for a layout, where
+// substituting adjacent keys costs half an edit
+func NewKeyboardDistance(layout *KeyboardLayout) *KeyboardDistance {
+	return &KeyboardDistance{
+		Layout:       layout,
+		AdjacentCost: defaultAdjacentCost,
+	}
+}
+
+// Weighted takes two strings and a maximum cost and returns the weighted cost
+// of the edits to transform one string to another, or -1 if the cost is
+// greater than the maximum cost.
+func (kd *KeyboardDistance) Weighted(str1, str2 string, maxCost float64) float64 {
+	return kd.WeightedRunes([]rune(str1), []rune(str2), maxCost)
+}
+
+// WeightedRunes is the same as Weighted but accepts runes instead of strings
+func (kd *KeyboardDistance) WeightedRunes(r1, r2 []rune, maxCost float64) float64 {
+	return kd.WeightedRunesBuffer(r1, r2, maxCost, nil, nil, nil)
+}
+
+// WeightedRunesBuffer is the same as WeightedRunes but also accepts memory
+// buffers x, y and z which should each be of size len(r2)+1
+func (kd *KeyboardDistance) WeightedRunesBuffer(r1, r2 []rune, maxCost float64, x, y, z []float64) float64 {
+	if CompareSlices(r1, r2) {
+		return 0
+	}
+
+	r1Len := len(r1)
+	r2Len := len(r2)
+
+	if math.Abs(float64(r1Len-r2Len)) > maxCost {
+		return -1
+	}
+
+	if len(x) < r2Len+1 {
+		x = make([]float64, r2Len+1)
+	}
+	if len(y) < r2Len+1 {
+		y = make([]float64, r2Len+1)
+	}
+	if len(z) < r2Len+1 {
+		z = make([]float64, r2Len+1)
+	}
+
+	// x holds the previous row, y the current row and z the row before x,
+	// which is needed for transpositions
+	for j := 0; j <= r2Len; j++ {
+		x[j] = float64(j)
+	}
+
+	for i := 1; i <= r1Len; i++ {
+		y[0] = float64(i)
+		rowMin := y[0]
+
+		for j := 1; j <= r2Len; j++ {
+			cost := 0.0
+			if r1[i-1] != r2[j-1] {
+				cost = kd.substitutionCost(r1[i-1], r2[j-1])
+			}
+
+			current := x[j-1] + cost
+			if del := x[j] + 1; del < current {
+				current = del
+			}
+			if ins := y[j-1] + 1; ins < current {
+				current = ins
+			}
+			if i > 1 && j > 1 && r1[i-1] == r2[j-2] && r1[i-2] == r2[j-1] {
+				if trans := z[j-2] + 1; trans < current {
+					current = trans
+				}
+			}
+
+			y[j] = current
+			if current < rowMin {
+				rowMin = current
+			}
+		}
+
+		if rowMin > maxCost {
+			return -1
+		}
+
+		z, x, y = x, y, z
+	}
+
+	if x[r2Len] > maxCost {
+		return -1
+	}
+
+	return x[r2Len]
+}
+
+// Runes adapts the weighted cost to the contract of the other distance
+// functions, rounding it up to a whole number of edits, so it can be used
+// with DistanceFunc
+func (kd *KeyboardDistance) Runes(r1, r2 []rune, maxDist int) int {
+	cost := kd.WeightedRunes(r1, r2, float64(maxDist))
+	if cost < 0 {
+		return -1
+	}
+	return int(math.Ceil(cost))
+}
+
+func (kd *KeyboardDistance) substitutionCost(r1, r2 rune) float64 {
+	if kd.Layout != nil && kd.Layout.Adjacent(r1, r2) {
+		return kd.AdjacentCost
+	}
+	return 1
+}
@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestKeyboardLayout_Adjacent(t *testing.T) {
+	tests := []struct {
+		layout string
+		r1, r2 rune
+		want   bool
+	}{
+		{QWERTY, 'o', 'p', true},
+		{QWERTY, 'a', 'q', true},
+		{QWERTY, 'a', 'z', true},
+		{QWERTY, 'a', 'x', false},
+		{QWERTY, 'p', 'a', false},
+		{QWERTY, 'G', 'h', true},
+		{AZERTY, 'a', 'z', true},
+		{AZERTY, 'q', 'w', true},
+		{AZERTY, 'a', 'w', false},
+		{DVORAK, 'a', 'o', true},
+		{DVORAK, 'o', 'p', false},
+	}
+
+	for i, d := range tests {
+		layout, ok := GetKeyboardLayout(d.layout)
+		if !ok {
+			t.Fatalf("Test[%d]: layout %q is not registered", i, d.layout)
+		}
+		if got := layout.Adjacent(d.r1, d.r2); got != d.want {
+			t.Errorf("Test[%d]: %s.Adjacent(%q,%q) returned %v, want %v",
+				i, d.layout, d.r1, d.r2, got, d.want)
+		}
+	}
+}
+
+func TestRegisterKeyboardLayout(t *testing.T) {
+	if _, err := NewKeyboardLayout("broken", []string{"abc"}, nil); err == nil {
+		t.Fatal("Expected an error for a layout without offsets")
+	}
+
+	layout, err := NewKeyboardLayout("abc", []string{"abc", "def"}, []float64{0, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	RegisterKeyboardLayout(layout)
+
+	registered, ok := GetKeyboardLayout("abc")
+	if !ok || registered != layout {
+		t.Fatal("Expected the layout to be registered")
+	}
+	if !registered.Adjacent('a', 'e') || registered.Adjacent('a', 'f') {
+		t.Fatal("Unexpected adjacency for custom layout")
+	}
+}
+
+func TestKeyboardDistance(t *testing.T) {
+	layout, _ := GetKeyboardLayout(QWERTY)
+	kd := NewKeyboardDistance(layout)
+
+	tests := []struct {
+		a, b    string
+		maxCost float64
+		want    float64
+	}{
+		{"", "", 10, 0},
+		{"", "testing", 10, 7},
+		{"testing", "", 10, 7},
+		{"testing", "testing", 10, 0},
+		{"wprd", "word", 10, 0.5},
+		{"wprd", "ward", 10, 1},
+		{"salt", "slat", 10, 1},
+		{"abcd", "efgh", 3, -1},
+		{"abcd", "efgh", 4, 4},
+		{"qwer", "asdf", 10, 2},
+		{"Kätzchen", "Katzchen", 10, 1},
+	}
+
+	for i, d := range tests {
+		n := kd.Weighted(d.a, d.b, d.maxCost)
+		if n != d.want {
+			t.Errorf("Test[%d]: Weighted(%q,%q,%v) returned %v, want %v",
+				i, d.a, d.b, d.maxCost, n, d.want)
+		}
+
+		r1 := []rune(d.a)
+		r2 := []rune(d.b)
+
+		n2 := kd.WeightedRunes(r1, r2, d.maxCost)
+		if n != n2 {
+			t.Error("Weighted() is not equal to WeightedRunes()")
+		}
+
+		x := make([]float64, len(r2)+1)
+		y := make([]float64, len(r2)+1)
+		z := make([]float64, len(r2)+1)
+		n3 := kd.WeightedRunesBuffer(r1, r2, d.maxCost, x, y, z)
+		if n != n3 {
+			t.Error("Weighted() is not equal to WeightedRunesBuffer()")
+		}
+	}
+
+	if d := kd.Runes([]rune("wprd"), []rune("word"), 2); d != 1 {
+		t.Errorf("Runes() returned %v, want 1", d)
+	}
+}
+
+func BenchmarkKeyboardDistance(b *testing.B) {
+	layout, _ := GetKeyboardLayout(QWERTY)
+	kd := NewKeyboardDistance(layout)
+
+	tests := []struct {
+		a, b    string
+		maxCost float64
+		name    string
+	}{
+		{"levenshtein", "frankenstein", 10, "ASCII"},
+		{"Kätzchen", "Katzchen", 10, "UTF8"},
+	}
+	for _, test := range tests {
+		b.Run(test.name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				kd.Weighted(test.a, test.b, test.maxCost)
+			}
+		})
+		b.Run(test.name+"RunesBuffer", func(b *testing.B) {
+			r1 := []rune(test.a)
+			r2 := []rune(test.b)
+			x := make([]float64, len(r2)+1)
+			y := make([]float64, len(r2)+1)
+			z := make([]float64, len(r2)+1)
+			for n := 0; n < b.N; n++ {
+				kd.WeightedRunesBuffer(r1, r2, test.maxCost, x, y, z)
+			}
+		})
+	}
+}
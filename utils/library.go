@@ -51,3 +51,30 @@ func (l *Library) Remove(dict, word string) bool {
 	return false
 }
 
+
+
+// Words returns the words of a given dictionary
+func (l *Library) Words(dict string) []string {
+	l.RLock()
+	defer l.RUnlock()
+
+	words := make([]string, 0, len(l.Dictionaries[dict]))
+	for word := range l.Dictionaries[dict] {
+		words = append(words, word)
+	}
+
+	return words
+}
+
+// Names returns the names of the dictionaries
+func (l *Library) Names() []string {
+	l.RLock()
+	defer l.RUnlock()
+
+	names := make([]string, 0, len(l.Dictionaries))
+	for name := range l.Dictionaries {
+		names = append(names, name)
+	}
+
+	return names
+}
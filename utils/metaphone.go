@@ -0,0 +1,654 @@
+package utils
+
+import (
+	"strings"
+)
+
+// DoubleMetaphone is Lawrence Philips' Double Metaphone phonetic encoding. Each
+// word has a primary code and, for words of uncertain origin, an alternate
+// code, e.g. "Smith" -> "SM0" and "XMT".
+type DoubleMetaphone struct{}
+
+const metaphoneMaxLen = 4
+
+// Name returns the name the encoder is registered with
+func (DoubleMetaphone) Name() string {
+	return DoubleMetaphoneEncoder
+}
+
+// Encode returns the primary code of word followed by its alternate code if
+// it is different
+func (DoubleMetaphone) Encode(word string) []string {
+	primary, alternate := DoubleMetaphoneCodes(word)
+	if primary == "" {
+		return nil
+	}
+	if alternate == "" || alternate == primary {
+		return []string{primary}
+	}
+	return []string{primary, alternate}
+}
+
+// DoubleMetaphoneCodes returns the primary and alternate Double Metaphone
+// codes of word
+func DoubleMetaphoneCodes(word string) (string, string) {
+	value := []rune(strings.ToUpper(strings.TrimSpace(word)))
+	if len(value) == 0 {
+		return "", ""
+	}
+
+	m := &metaphone{
+		value:         value,
+		slavoGermanic: isSlavoGermanic(string(value)),
+	}
+
+	index := 0
+	if m.contains(0, "GN", "KN", "PN", "WR", "PS") {
+		index = 1
+	}
+
+	for !m.isComplete() && index < len(value) {
+		switch value[index] {
+		case 'A', 'E', 'I', 'O', 'U', 'Y':
+			if index == 0 {
+				m.add("A")
+			}
+			index++
+		case 'B':
+			m.add("P")
+			index = m.skipDouble(index, 'B')
+		case 'Ç':
+			m.add("S")
+			index++
+		case 'C':
+			index = m.handleC(index)
+		case 'D':
+			index = m.handleD(index)
+		case 'F':
+			m.add("F")
+			index = m.skipDouble(index, 'F')
+		case 'G':
+			index = m.handleG(index)
+		case 'H':
+			index = m.handleH(index)
+		case 'J':
+			index = m.handleJ(index)
+		case 'K':
+			m.add("K")
+			index = m.skipDouble(index, 'K')
+		case 'L':
+			index = m.handleL(index)
+		case 'M':
+			m.add("M")
+			if m.conditionM0(index) {
+				index += 2
+			} else {
+				index++
+			}
+		case 'N':
+			m.add("N")
+			index = m.skipDouble(index, 'N')
+		case 'Ñ':
+			m.add("N")
+			index++
+		case 'P':
+			index = m.handleP(index)
+		case 'Q':
+			m.add("K")
+			index = m.skipDouble(index, 'Q')
+		case 'R':
+			index = m.handleR(index)
+		case 'S':
+			index = m.handleS(index)
+		case 'T':
+			index = m.handleT(index)
+		case 'V':
+			m.add("F")
+			index = m.skipDouble(index, 'V')
+		case 'W':
+			index = m.handleW(index)
+		case 'X':
+			index = m.handleX(index)
+		case 'Z':
+			index = m.handleZ(index)
+		default:
+			index++
+		}
+	}
+
+	return m.primary.String(), m.alternate.String()
+}
+
+type metaphone struct {
+	value         []rune
+	slavoGermanic bool
+	primary       strings.Builder
+	alternate     strings.Builder
+}
+
+func isSlavoGermanic(value string) bool {
+	return strings.ContainsAny(value, "WK") || strings.Contains(value, "CZ") ||
+		strings.Contains(value, "WITZ")
+}
+
+func isMetaphoneVowel(r rune) bool {
+	return strings.ContainsRune("AEIOUY", r)
+}
+
+func (m *metaphone) isComplete() bool {
+	return m.primary.Len() >= metaphoneMaxLen && m.alternate.Len() >= metaphoneMaxLen
+}
+
+func appendCode(b *strings.Builder, code string) {
+	if free := metaphoneMaxLen - b.Len(); free > 0 {
+		if len(code) > free {
+			code = code[:free]
+		}
+		b.WriteString(code)
+	}
+}
+
+// add appends code to both the primary and alternate codes
+func (m *metaphone) add(code string) {
+	m.addBoth(code, code)
+}
+
+func (m *metaphone) addBoth(primary, alternate string) {
+	appendCode(&m.primary, primary)
+	appendCode(&m.alternate, alternate)
+}
+
+func (m *metaphone) addAlternate(code string) {
+	appendCode(&m.alternate, code)
+}
+
+func (m *metaphone) addPrimary(code string) {
+	appendCode(&m.primary, code)
+}
+
+func (m *metaphone) charAt(index int) rune {
+	if index < 0 || index >= len(m.value) {
+		return 0
+	}
+	return m.value[index]
+}
+
+func (m *metaphone) isVowelAt(index int) bool {
+	return isMetaphoneVowel(m.charAt(index))
+}
+
+func (m *metaphone) last() int {
+	return len(m.value) - 1
+}
+
+// contains reports whether the value holds any of the criteria at start. All
+// criteria must have the same length.
+func (m *metaphone) contains(start int, criteria ...string) bool {
+	length := len([]rune(criteria[0]))
+	if start < 0 || start+length > len(m.value) {
+		return false
+	}
+
+	target := string(m.value[start : start+length])
+	for _, c := range criteria {
+		if target == c {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *metaphone) skipDouble(index int, r rune) int {
+	if m.charAt(index+1) == r {
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleC(index int) int {
+	switch {
+	case m.conditionC0(index):
+		m.add("K")
+		index += 2
+	case index == 0 && m.contains(index, "CAESAR"):
+		m.add("S")
+		index += 2
+	case m.contains(index, "CH"):
+		index = m.handleCH(index)
+	case m.contains(index, "CZ") && !m.contains(index-2, "WICZ"):
+		// "Czerny"
+		m.addBoth("S", "X")
+		index += 2
+	case m.contains(index+1, "CIA"):
+		// "focaccia"
+		m.add("X")
+		index += 3
+	case m.contains(index, "CC") && !(index == 1 && m.charAt(0) == 'M'):
+		// double "cc" but not "McClelland"
+		return m.handleCC(index)
+	case m.contains(index, "CK", "CG", "CQ"):
+		m.add("K")
+		index += 2
+	case m.contains(index, "CI", "CE", "CY"):
+		// Italian vs. English
+		if m.contains(index, "CIO", "CIE", "CIA") {
+			m.addBoth("S", "X")
+		} else {
+			m.add("S")
+		}
+		index += 2
+	default:
+		m.add("K")
+		if m.contains(index+1, " C", " Q", " G") {
+			// "Mac Caffrey", "Mac Gregor"
+			index += 3
+		} else if m.contains(index+1, "C", "K", "Q") && !m.contains(index+1, "CE", "CI") {
+			index += 2
+		} else {
+			index++
+		}
+	}
+	return index
+}
+
+func (m *metaphone) handleCC(index int) int {
+	if m.contains(index+2, "I", "E", "H") && !m.contains(index+2, "HU") {
+		// "bellocchio" but not "bacchus"
+		if (index == 1 && m.charAt(index-1) == 'A') || m.contains(index-1, "UCCEE", "UCCES") {
+			// "accident", "accede", "succeed"
+			m.add("KS")
+		} else {
+			// "bacci", "bertucci", other Italian
+			m.add("X")
+		}
+		return index + 3
+	}
+
+	// Pierce's rule
+	m.add("K")
+	return index + 2
+}
+
+func (m *metaphone) handleCH(index int) int {
+	switch {
+	case index > 0 && m.contains(index, "CHAE"):
+		// "Michael"
+		m.addBoth("K", "X")
+	case m.conditionCH0(index), m.conditionCH1(index):
+		// Greek roots, or Germanic 'ch' for 'kh' sound
+		m.add("K")
+	case index > 0:
+		if m.contains(0, "MC") {
+			m.add("K")
+		} else {
+			m.addBoth("X", "K")
+		}
+	default:
+		m.add("X")
+	}
+	return index + 2
+}
+
+func (m *metaphone) handleD(index int) int {
+	switch {
+	case m.contains(index, "DG"):
+		if m.contains(index+2, "I", "E", "Y") {
+			// "edge"
+			m.add("J")
+			return index + 3
+		}
+		// "Edgar"
+		m.add("TK")
+		return index + 2
+	case m.contains(index, "DT", "DD"):
+		m.add("T")
+		return index + 2
+	default:
+		m.add("T")
+		return index + 1
+	}
+}
+
+func (m *metaphone) handleG(index int) int {
+	switch {
+	case m.charAt(index+1) == 'H':
+		return m.handleGH(index)
+	case m.charAt(index+1) == 'N':
+		if index == 1 && m.isVowelAt(0) && !m.slavoGermanic {
+			m.addBoth("KN", "N")
+		} else if !m.contains(index+2, "EY") && m.charAt(index+1) != 'Y' && !m.slavoGermanic {
+			m.addBoth("N", "KN")
+		} else {
+			m.add("KN")
+		}
+		return index + 2
+	case m.contains(index+1, "LI") && !m.slavoGermanic:
+		m.addBoth("KL", "L")
+		return index + 2
+	case index == 0 && (m.charAt(index+1) == 'Y' ||
+		m.contains(index+1, "ES", "EP", "EB", "EL", "EY", "IB", "IL", "IN", "IE", "EI", "ER")):
+		// -ges-, -gep-, -gel-, -gie- at beginning
+		m.addBoth("K", "J")
+		return index + 2
+	case (m.contains(index+1, "ER") || m.charAt(index+1) == 'Y') &&
+		!m.contains(0, "DANGER", "RANGER", "MANGER") &&
+		!m.contains(index-1, "E", "I") &&
+		!m.contains(index-1, "RGY", "OGY"):
+		// -ger-, -gy-
+		m.addBoth("K", "J")
+		return index + 2
+	case m.contains(index+1, "E", "I", "Y") || m.contains(index-1, "AGGI", "OGGI"):
+		// Italian "biaggi"
+		if m.contains(0, "VAN ", "VON ") || m.contains(0, "SCH") || m.contains(index+1, "ET") {
+			// obvious Germanic
+			m.add("K")
+		} else if m.contains(index+1, "IER") {
+			m.add("J")
+		} else {
+			m.addBoth("J", "K")
+		}
+		return index + 2
+	case m.charAt(index+1) == 'G':
+		m.add("K")
+		return index + 2
+	default:
+		m.add("K")
+		return index + 1
+	}
+}
+
+func (m *metaphone) handleGH(index int) int {
+	switch {
+	case index > 0 && !m.isVowelAt(index-1):
+		m.add("K")
+	case index == 0:
+		if m.charAt(index+2) == 'I' {
+			m.add("J")
+		} else {
+			m.add("K")
+		}
+	case (index > 1 && m.contains(index-2, "B", "H", "D")) ||
+		(index > 2 && m.contains(index-3, "B", "H", "D")) ||
+		(index > 3 && m.contains(index-4, "B", "H")):
+		// Parker's rule, "hugh"
+	default:
+		if index > 2 && m.charAt(index-1) == 'U' && m.contains(index-3, "C", "G", "L", "R", "T") {
+			// "laugh", "McLaughlin", "cough", "gough", "rough", "tough"
+			m.add("F")
+		} else if index > 0 && m.charAt(index-1) != 'I' {
+			m.add("K")
+		}
+	}
+	return index + 2
+}
+
+func (m *metaphone) handleH(index int) int {
+	// Only keep if first and before a vowel or between two vowels
+	if (index == 0 || m.isVowelAt(index-1)) && m.isVowelAt(index+1) {
+		m.add("H")
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleJ(index int) int {
+	if m.contains(index, "JOSE") || m.contains(0, "SAN ") {
+		// Obvious Spanish, "Jose", "San Jacinto"
+		if (index == 0 && m.charAt(index+4) == ' ') || len(m.value) == 4 || m.contains(0, "SAN ") {
+			m.add("H")
+		} else {
+			m.addBoth("J", "H")
+		}
+		return index + 1
+	}
+
+	switch {
+	case index == 0:
+		m.addBoth("J", "A")
+	case m.isVowelAt(index-1) && !m.slavoGermanic &&
+		(m.charAt(index+1) == 'A' || m.charAt(index+1) == 'O'):
+		m.addBoth("J", "H")
+	case index == m.last():
+		m.addBoth("J", "")
+	case !m.contains(index+1, "L", "T", "K", "S", "N", "M", "B", "Z") &&
+		!m.contains(index-1, "S", "K", "L"):
+		m.add("J")
+	}
+
+	return m.skipDouble(index, 'J')
+}
+
+func (m *metaphone) handleL(index int) int {
+	if m.charAt(index+1) == 'L' {
+		if m.conditionL0(index) {
+			m.addPrimary("L")
+		} else {
+			m.add("L")
+		}
+		return index + 2
+	}
+	m.add("L")
+	return index + 1
+}
+
+func (m *metaphone) handleP(index int) int {
+	if m.charAt(index+1) == 'H' {
+		m.add("F")
+		return index + 2
+	}
+	m.add("P")
+	if m.contains(index+1, "P", "B") {
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleR(index int) int {
+	if index == m.last() && !m.slavoGermanic &&
+		m.contains(index-2, "IE") && !m.contains(index-4, "ME", "MA") {
+		// French, e.g. "Rogier"
+		m.addAlternate("R")
+	} else {
+		m.add("R")
+	}
+	return m.skipDouble(index, 'R')
+}
+
+func (m *metaphone) handleS(index int) int {
+	switch {
+	case m.contains(index-1, "ISL", "YSL"):
+		// "island", "isle", "carlisle", "carlysle"
+		return index + 1
+	case index == 0 && m.contains(index, "SUGAR"):
+		m.addBoth("X", "S")
+		return index + 1
+	case m.contains(index, "SH"):
+		if m.contains(index+1, "HEIM", "HOEK", "HOLM", "HOLZ") {
+			// Germanic
+			m.add("S")
+		} else {
+			m.add("X")
+		}
+		return index + 2
+	case m.contains(index, "SIO", "SIA") || m.contains(index, "SIAN"):
+		// Italian and Armenian
+		if m.slavoGermanic {
+			m.add("S")
+		} else {
+			m.addBoth("S", "X")
+		}
+		return index + 3
+	case (index == 0 && m.contains(index+1, "M", "N", "L", "W")) || m.contains(index+1, "Z"):
+		// German and anglicisations, e.g. "smith" matches "schmidt"
+		m.addBoth("S", "X")
+		if m.contains(index+1, "Z") {
+			return index + 2
+		}
+		return index + 1
+	case m.contains(index, "SC"):
+		return m.handleSC(index)
+	default:
+		if index == m.last() && m.contains(index-2, "AI", "OI") {
+			// French, e.g. "resnais", "artois"
+			m.addAlternate("S")
+		} else {
+			m.add("S")
+		}
+		if m.contains(index+1, "S", "Z") {
+			return index + 2
+		}
+		return index + 1
+	}
+}
+
+func (m *metaphone) handleSC(index int) int {
+	switch {
+	case m.charAt(index+2) == 'H':
+		// Schlesinger's rule
+		if m.contains(index+3, "OO", "ER", "EN", "UY", "ED", "EM") {
+			// Dutch origin, e.g. "school", "schooner"
+			if m.contains(index+3, "ER", "EN") {
+				// "schermerhorn", "schenker"
+				m.addBoth("X", "SK")
+			} else {
+				m.add("SK")
+			}
+		} else if index == 0 && !m.isVowelAt(3) && m.charAt(3) != 'W' {
+			m.addBoth("X", "S")
+		} else {
+			m.add("X")
+		}
+	case m.contains(index+2, "I", "E", "Y"):
+		m.add("S")
+	default:
+		m.add("SK")
+	}
+	return index + 3
+}
+
+func (m *metaphone) handleT(index int) int {
+	switch {
+	case m.contains(index, "TION"), m.contains(index, "TIA", "TCH"):
+		m.add("X")
+		return index + 3
+	case m.contains(index, "TH") || m.contains(index, "TTH"):
+		if m.contains(index+2, "OM", "AM") || m.contains(0, "VAN ", "VON ") || m.contains(0, "SCH") {
+			// "thomas", "thames" or Germanic
+			m.add("T")
+		} else {
+			m.addBoth("0", "T")
+		}
+		return index + 2
+	default:
+		m.add("T")
+		if m.contains(index+1, "T", "D") {
+			return index + 2
+		}
+		return index + 1
+	}
+}
+
+func (m *metaphone) handleW(index int) int {
+	switch {
+	case m.contains(index, "WR"):
+		m.add("R")
+		return index + 2
+	case index == 0 && (m.isVowelAt(index+1) || m.contains(index, "WH")):
+		if m.isVowelAt(index + 1) {
+			// "Wasserman" should match "Vasserman"
+			m.addBoth("A", "F")
+		} else {
+			// "Uomo" should match "Womo"
+			m.add("A")
+		}
+		return index + 1
+	case (index == m.last() && m.isVowelAt(index-1)) ||
+		m.contains(index-1, "EWSKI", "EWSKY", "OWSKI", "OWSKY") ||
+		m.contains(0, "SCH"):
+		// "Arnow" should match "Arnoff"
+		m.addAlternate("F")
+		return index + 1
+	case m.contains(index, "WICZ", "WITZ"):
+		// Polish, e.g. "filipowicz"
+		m.addBoth("TS", "FX")
+		return index + 4
+	default:
+		return index + 1
+	}
+}
+
+func (m *metaphone) handleX(index int) int {
+	if index == 0 {
+		m.add("S")
+		return index + 1
+	}
+
+	if !(index == m.last() && (m.contains(index-3, "IAU", "EAU") || m.contains(index-2, "AU", "OU"))) {
+		// Not French, e.g. "breaux"
+		m.add("KS")
+	}
+
+	if m.contains(index+1, "C", "X") {
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleZ(index int) int {
+	if m.charAt(index+1) == 'H' {
+		// Chinese pinyin, e.g. "zhao"
+		m.add("J")
+		return index + 2
+	}
+
+	if m.contains(index+1, "ZO", "ZI", "ZA") || (m.slavoGermanic && index > 0 && m.charAt(index-1) != 'T') {
+		m.addBoth("S", "TS")
+	} else {
+		m.add("S")
+	}
+	return m.skipDouble(index, 'Z')
+}
+
+func (m *metaphone) conditionC0(index int) bool {
+	if m.contains(index, "CHIA") {
+		return true
+	}
+	if index <= 1 || m.isVowelAt(index-2) || !m.contains(index-1, "ACH") {
+		return false
+	}
+	c := m.charAt(index + 2)
+	return (c != 'I' && c != 'E') || m.contains(index-2, "BACHER", "MACHER")
+}
+
+func (m *metaphone) conditionCH0(index int) bool {
+	if index != 0 {
+		return false
+	}
+	if !m.contains(index+1, "HARAC", "HARIS") && !m.contains(index+1, "HOR", "HYM", "HIA", "HEM") {
+		return false
+	}
+	return !m.contains(0, "CHORE")
+}
+
+func (m *metaphone) conditionCH1(index int) bool {
+	return m.contains(0, "VAN ", "VON ") || m.contains(0, "SCH") ||
+		m.contains(index-2, "ORCHES", "ARCHIT", "ORCHID") ||
+		m.contains(index+2, "T", "S") ||
+		((m.contains(index-1, "A", "O", "U", "E") || index == 0) &&
+			(m.contains(index+2, "L", "R", "N", "M", "B", "H", "F", "V", "W", " ") || index+1 == m.last()))
+}
+
+func (m *metaphone) conditionL0(index int) bool {
+	if index == len(m.value)-3 && m.contains(index-1, "ILLO", "ILLA", "ALLE") {
+		return true
+	}
+	return (m.contains(len(m.value)-2, "AS", "OS") || m.contains(len(m.value)-1, "A", "O")) &&
+		m.contains(index-1, "ALLE")
+}
+
+func (m *metaphone) conditionM0(index int) bool {
+	if m.charAt(index+1) == 'M' {
+		return true
+	}
+	return m.contains(index-1, "UMB") && (index+1 == m.last() || m.contains(index+2, "ER"))
+}
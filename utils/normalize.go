@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Unicode normalization forms
+const (
+	NFC  = "NFC"
+	NFD  = "NFD"
+	NFKC = "NFKC"
+	NFKD = "NFKD"
+)
+
+var normForms = map[string]norm.Form{
+	NFC:  norm.NFC,
+	NFD:  norm.NFD,
+	NFKC: norm.NFKC,
+	NFKD: norm.NFKD,
+}
+
+// Normalizer turns words into the keys they are indexed and looked up with.
+// A nil Normalizer leaves words unchanged. Its fields must not be changed once
+// it is in use.
+type Normalizer struct {
+	// Unicode normalization form, one of NFC, NFD, NFKC or NFKD. Empty
+	// leaves the form unchanged
+	Form string `json:",omitempty"`
+	// Whether case differences should be ignored
+	CaseFold bool `json:",omitempty"`
+	// BCP 47 tag of the language whose casing rules are used for case
+	// folding, e.g. "tr" folds "I" to "ı". Empty uses Unicode case folding
+	Locale string `json:",omitempty"`
+	// Whether accents and other combining marks should be removed, e.g.
+	// "café" becomes "cafe"
+	FoldDiacritics bool `json:",omitempty"`
+
+	// Casers for the locale, kept between calls to Normalize
+	casers sync.Pool
+}
+
+// Validate returns an error if the form or locale of the normalizer is
+// unknown
+func (n *Normalizer) Validate() error {
+	if n == nil {
+		return nil
+	}
+
+	if _, exists := normForms[n.Form]; n.Form != "" && !exists {
+		return fmt.Errorf("unknown normalization form %q", n.Form)
+	}
+
+	if n.Locale != "" {
+		if _, err := language.Parse(n.Locale); err != nil {
+			return fmt.Errorf("invalid locale %q: %v", n.Locale, err)
+		}
+	}
+
+	return nil
+}
+
+// Normalize returns the key of str
+func (n *Normalizer) Normalize(str string) string {
+	if n == nil {
+		return str
+	}
+
+	form, hasForm := normForms[n.Form]
+	if hasForm {
+		str = form.String(str)
+	}
+
+	if n.CaseFold {
+		caser := n.caser()
+		str = caser.String(str)
+		n.casers.Put(caser)
+	}
+
+	if n.FoldDiacritics {
+		// Decompose so that marks are separate runes, drop them and compose
+		// what is left
+		t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+		str, _, _ = transform.String(t, str)
+	}
+
+	// Folding may leave the string in a different form
+	if hasForm && (n.CaseFold || n.FoldDiacritics) {
+		str = form.String(str)
+	}
+
+	return str
+}
+
+// caser returns a caser for the locale that no other goroutine is using, since
+// casers can't be shared between goroutines. It should be put back in
+// n.casers once used.
+func (n *Normalizer) caser() *cases.Caser {
+	if caser, ok := n.casers.Get().(*cases.Caser); ok {
+		return caser
+	}
+
+	var caser cases.Caser
+	if n.Locale == "" {
+		caser = cases.Fold()
+	} else {
+		caser = cases.Lower(language.Make(n.Locale))
+	}
+	return &caser
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestNormalizer(t *testing.T) {
+	tests := []struct {
+		normalizer *Normalizer
+		str        string
+		want       string
+	}{
+		{nil, "Café", "Café"},
+		{&Normalizer{Form: NFC}, "cafe\u0301", "caf\u00e9"},
+		{&Normalizer{Form: NFD}, "caf\u00e9", "cafe\u0301"},
+		{&Normalizer{Form: NFKC}, "ﬁne", "fine"},
+		{&Normalizer{CaseFold: true}, "Straße", "strasse"},
+		{&Normalizer{CaseFold: true}, "DİYARBAKIR", "di̇yarbakir"},
+		{&Normalizer{CaseFold: true, Locale: "tr"}, "DİYARBAKIR", "diyarbakır"},
+		{&Normalizer{FoldDiacritics: true}, "Crème brûlée", "Creme brulee"},
+		{&Normalizer{Form: NFC, CaseFold: true, FoldDiacritics: true}, "CAFÉ", "cafe"},
+	}
+
+	for i, d := range tests {
+		if got := d.normalizer.Normalize(d.str); got != d.want {
+			t.Errorf("Test[%d]: Normalize(%q) returned %q, want %q", i, d.str, got, d.want)
+		}
+	}
+}
+
+func TestNormalizer_Validate(t *testing.T) {
+	if err := (&Normalizer{Form: NFKC, Locale: "tr"}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&Normalizer{Form: "NFX"}).Validate(); err == nil {
+		t.Fatal("Expected an error for an unknown form")
+	}
+	if err := (&Normalizer{Locale: "not a locale"}).Validate(); err == nil {
+		t.Fatal("Expected an error for an invalid locale")
+	}
+}
+
+func TestNormalizer_concurrent(t *testing.T) {
+	n := &Normalizer{CaseFold: true, Locale: "tr"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if got := n.Normalize("DİYARBAKIR"); got != "diyarbakır" {
+					t.Errorf("Normalize returned %q, want diyarbakır", got)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"sort"
+	"sync"
+)
+
+// Built-in phonetic encoders
+const (
+	SoundexEncoder         = "soundex"
+	DoubleMetaphoneEncoder = "doublemetaphone"
+)
+
+// PhoneticEncoder encodes words so that words that sound alike share a code.
+// A word may have several codes, and none if it can't be encoded.
+type PhoneticEncoder interface {
+	Name() string
+	Encode(word string) []string
+}
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]PhoneticEncoder{}
+)
+
+func init() {
+	RegisterPhoneticEncoder(Soundex{})
+	RegisterPhoneticEncoder(DoubleMetaphone{})
+}
+
+// RegisterPhoneticEncoder makes an encoder available through
+// GetPhoneticEncoder, replacing any encoder registered with the same name
+func RegisterPhoneticEncoder(encoder PhoneticEncoder) {
+	encodersMu.Lock()
+	encoders[encoder.Name()] = encoder
+	encodersMu.Unlock()
+}
+
+// GetPhoneticEncoder returns the encoder registered with name
+func GetPhoneticEncoder(name string) (PhoneticEncoder, bool) {
+	encodersMu.RLock()
+	encoder, exists := encoders[name]
+	encodersMu.RUnlock()
+	return encoder, exists
+}
+
+// PhoneticIndex maps phonetic codes to the words that have them
+type PhoneticIndex struct {
+	sync.RWMutex
+	Encoder PhoneticEncoder
+	codes   map[string]map[string]struct{}
+}
+
+// NewPhoneticIndex creates a new, empty phonetic index
+func NewPhoneticIndex(encoder PhoneticEncoder) *PhoneticIndex {
+	return &PhoneticIndex{
+		Encoder: encoder,
+		codes:   make(map[string]map[string]struct{}),
+	}
+}
+
+// Add a word to the index
+func (pi *PhoneticIndex) Add(word string) {
+	codes := pi.Encoder.Encode(word)
+
+	pi.Lock()
+	for _, code := range codes {
+		if _, exists := pi.codes[code]; !exists {
+			pi.codes[code] = make(map[string]struct{})
+		}
+		pi.codes[code][word] = struct{}{}
+	}
+	pi.Unlock()
+}
+
+// Remove a word from the index
+func (pi *PhoneticIndex) Remove(word string) {
+	codes := pi.Encoder.Encode(word)
+
+	pi.Lock()
+	for _, code := range codes {
+		delete(pi.codes[code], word)
+		if len(pi.codes[code]) == 0 {
+			delete(pi.codes, code)
+		}
+	}
+	pi.Unlock()
+}
+
+// Load returns the sorted words of the index that share a code with word
+func (pi *PhoneticIndex) Load(word string) []string {
+	codes := pi.Encoder.Encode(word)
+	found := make(map[string]struct{})
+
+	pi.RLock()
+	for _, code := range codes {
+		for w := range pi.codes[code] {
+			found[w] = struct{}{}
+		}
+	}
+	pi.RUnlock()
+
+	words := make([]string, 0, len(found))
+	for w := range found {
+		words = append(words, w)
+	}
+	sort.Strings(words)
+
+	return words
+}
+
+// PhoneticIndexes holds the phonetic index of each dictionary that has one
+type PhoneticIndexes struct {
+	sync.RWMutex
+	indexes map[string]*PhoneticIndex
+}
+
+// NewPhoneticIndexes creates a new, empty collection of phonetic indexes
+func NewPhoneticIndexes() *PhoneticIndexes {
+	return &PhoneticIndexes{
+		indexes: make(map[string]*PhoneticIndex),
+	}
+}
+
+// Load returns the phonetic index of a given dictionary
+func (p *PhoneticIndexes) Load(dict string) (*PhoneticIndex, bool) {
+	p.RLock()
+	index, exists := p.indexes[dict]
+	p.RUnlock()
+	return index, exists
+}
+
+// Store sets the phonetic index of a given dictionary
+func (p *PhoneticIndexes) Store(dict string, index *PhoneticIndex) {
+	p.Lock()
+	p.indexes[dict] = index
+	p.Unlock()
+}
+
+// Delete removes the phonetic index of a given dictionary
+func (p *PhoneticIndexes) Delete(dict string) {
+	p.Lock()
+	delete(p.indexes, dict)
+	p.Unlock()
+}
+
+// Encoders returns the name of the encoder of each dictionary's index
+func (p *PhoneticIndexes) Encoders() map[string]string {
+	p.RLock()
+	defer p.RUnlock()
+
+	names := make(map[string]string, len(p.indexes))
+	for dict, index := range p.indexes {
+		names[dict] = index.Encoder.Name()
+	}
+	return names
+}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSoundex(t *testing.T) {
+	tests := []struct {
+		word string
+		want []string
+	}{
+		{"Robert", []string{"R163"}},
+		{"Rupert", []string{"R163"}},
+		{"Rubin", []string{"R150"}},
+		{"Ashcraft", []string{"A261"}},
+		{"Tymczak", []string{"T522"}},
+		{"Pfister", []string{"P236"}},
+		{"Lee", []string{"L000"}},
+		{"123", nil},
+	}
+
+	for i, d := range tests {
+		if got := (Soundex{}).Encode(d.word); !reflect.DeepEqual(got, d.want) {
+			t.Errorf("Test[%d]: Soundex.Encode(%q) returned %v, want %v",
+				i, d.word, got, d.want)
+		}
+	}
+}
+
+func TestDoubleMetaphone(t *testing.T) {
+	tests := []struct {
+		word               string
+		primary, alternate string
+	}{
+		{"Smith", "SM0", "XMT"},
+		{"Schmidt", "XMT", "SMT"},
+		{"Thomas", "TMS", "TMS"},
+		{"phonetic", "FNTK", "FNTK"},
+		{"fonetik", "FNTK", "FNTK"},
+		{"knowledge", "NLJ", "NLJ"},
+		{"nollij", "NLJ", "NL"},
+		{"Xavier", "SF", "SFR"},
+		{"Jose", "HS", "HS"},
+		{"", "", ""},
+	}
+
+	for i, d := range tests {
+		primary, alternate := DoubleMetaphoneCodes(d.word)
+		if primary != d.primary || alternate != d.alternate {
+			t.Errorf("Test[%d]: DoubleMetaphoneCodes(%q) returned %q, %q, want %q, %q",
+				i, d.word, primary, alternate, d.primary, d.alternate)
+		}
+	}
+
+	if got := (DoubleMetaphone{}).Encode("Smith"); !reflect.DeepEqual(got, []string{"SM0", "XMT"}) {
+		t.Errorf("DoubleMetaphone.Encode returned %v", got)
+	}
+}
+
+func TestPhoneticIndex(t *testing.T) {
+	index := NewPhoneticIndex(DoubleMetaphone{})
+	index.Add("Smith")
+	index.Add("Schmidt")
+	index.Add("knowledge")
+
+	if got := index.Load("Smyth"); !reflect.DeepEqual(got, []string{"Schmidt", "Smith"}) {
+		t.Errorf("Expected [Schmidt Smith], got %v", got)
+	}
+
+	index.Remove("Schmidt")
+	if got := index.Load("Smyth"); !reflect.DeepEqual(got, []string{"Smith"}) {
+		t.Errorf("Expected [Smith], got %v", got)
+	}
+
+	if _, ok := GetPhoneticEncoder(SoundexEncoder); !ok {
+		t.Error("Expected soundex to be registered")
+	}
+}
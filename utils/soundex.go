@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"unicode"
+)
+
+// Soundex is the American Soundex phonetic encoding. Each word is encoded as
+// its first letter followed by three digits, e.g. "Robert" -> "R163".
+type Soundex struct{}
+
+var soundexCodes = map[rune]byte{
+	'B': '1', 'F': '1', 'P': '1', 'V': '1',
+	'C': '2', 'G': '2', 'J': '2', 'K': '2', 'Q': '2', 'S': '2', 'X': '2', 'Z': '2',
+	'D': '3', 'T': '3',
+	'L': '4',
+	'M': '5', 'N': '5',
+	'R': '6',
+}
+
+// Name returns the name the encoder is registered with
+func (Soundex) Name() string {
+	return SoundexEncoder
+}
+
+// Encode returns the Soundex code of word, or nothing if word has no ASCII
+// letters
+func (Soundex) Encode(word string) []string {
+	code := make([]byte, 0, 4)
+	var last byte
+
+	for _, r := range word {
+		r = unicode.ToUpper(r)
+		if r < 'A' || r > 'Z' {
+			continue
+		}
+
+		digit, isConsonant := soundexCodes[r]
+
+		if len(code) == 0 {
+			code = append(code, byte(r))
+			last = digit
+			continue
+		}
+
+		switch {
+		case isConsonant:
+			if digit != last {
+				code = append(code, digit)
+			}
+			last = digit
+		case r != 'H' && r != 'W':
+			// Vowels separate consonants with the same code, H and W do not
+			last = 0
+		}
+
+		if len(code) == 4 {
+			break
+		}
+	}
+
+	if len(code) == 0 {
+		return nil
+	}
+
+	for len(code) < 4 {
+		code = append(code, '0')
+	}
+
+	return []string{string(code)}
+}
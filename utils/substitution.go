@@ -0,0 +1,294 @@
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// SubstitutionRule replaces the runes of From, as they appear in the input,
+// with the runes of To, as they appear in the word, for Cost edits. Rules are
+// one way: a rule from "rn" to "m" doesn't turn "m" into "rn".
+type SubstitutionRule struct {
+	From string
+	To   string
+	Cost float64
+}
+
+type substitutionRule struct {
+	from, to []rune
+	cost     float64
+}
+
+// RuleSet is a table of multi-rune substitution rules, such as the errors made
+// by optical character recognition. It measures the distance between strings
+// as a Damerau-Levenshtein distance where each rule is a single edit of its
+// own cost.
+type RuleSet struct {
+	rules   []substitutionRule
+	maxFrom int
+}
+
+// Default rules for the errors made by optical character recognition
+var ocrRules = []SubstitutionRule{
+	{From: "rn", To: "m", Cost: 1},
+	{From: "cl", To: "d", Cost: 1},
+	{From: "li", To: "h", Cost: 1},
+	{From: "vv", To: "w", Cost: 1},
+	{From: "ii", To: "u", Cost: 1},
+}
+
+// NewRuleSet creates a rule set from rules. Both sides of a rule must be set
+// and differ, and its cost must be greater than 0.
+func NewRuleSet(rules ...SubstitutionRule) (*RuleSet, error) {
+	rs := &RuleSet{}
+
+	for _, rule := range rules {
+		if rule.From == "" || rule.To == "" {
+			return nil, errors.New("substitution rule needs both a from and a to")
+		}
+		if rule.From == rule.To {
+			return nil, fmt.Errorf("substitution rule from %q to itself", rule.From)
+		}
+		if rule.Cost <= 0 {
+			return nil, errors.New("substitution rule cost must be greater than 0")
+		}
+
+		from := []rune(rule.From)
+		rs.rules = append(rs.rules, substitutionRule{
+			from: from,
+			to:   []rune(rule.To),
+			cost: rule.Cost,
+		})
+		rs.maxFrom = Max(rs.maxFrom, len(from))
+	}
+
+	return rs, nil
+}
+
+// OCRRuleSet returns a rule set with common errors of optical character
+// recognition, such as "rn" read instead of "m" or "cl" instead of "d"
+func OCRRuleSet() *RuleSet {
+	rs, _ := NewRuleSet(ocrRules...)
+	return rs
+}
+
+// ReadRuleSet reads a rule set from r. Each line holds the from and to sides
+// of a rule and optionally its cost, which is 1 by default, separated by
+// whitespace. Empty lines and lines starting with # are skipped.
+func ReadRuleSet(r io.Reader) (*RuleSet, error) {
+	var rules []SubstitutionRule
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("line %d: expected from, to and an optional cost", line)
+		}
+
+		rule := SubstitutionRule{From: fields[0], To: fields[1], Cost: 1}
+		if len(fields) == 3 {
+			cost, err := strconv.ParseFloat(fields[2], 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			rule.Cost = cost
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewRuleSet(rules...)
+}
+
+// LoadRuleSet reads a rule set from the file at filePath, see ReadRuleSet
+func LoadRuleSet(filePath string) (*RuleSet, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadRuleSet(f)
+}
+
+// Rules returns the rules of the rule set
+func (rs *RuleSet) Rules() []SubstitutionRule {
+	rules := make([]SubstitutionRule, 0, len(rs.rules))
+	for _, rule := range rs.rules {
+		rules = append(rules, SubstitutionRule{
+			From: string(rule.from),
+			To:   string(rule.to),
+			Cost: rule.cost,
+		})
+	}
+	return rules
+}
+
+// Weighted takes two strings and a maximum cost and returns the cost of the
+// edits and rules to transform one string to another, or -1 if the cost is
+// greater than the maximum cost.
+func (rs *RuleSet) Weighted(str1, str2 string, maxCost float64) float64 {
+	return rs.WeightedRunes([]rune(str1), []rune(str2), maxCost)
+}
+
+// WeightedRunes is the same as Weighted but accepts runes instead of strings
+func (rs *RuleSet) WeightedRunes(r1, r2 []rune, maxCost float64) float64 {
+	return rs.WeightedRunesBuffer(r1, r2, maxCost, nil)
+}
+
+// WeightedRunesBuffer is the same as WeightedRunes but also accepts a memory
+// buffer d which should be of size (len(r1)+1)*(len(r2)+1)
+func (rs *RuleSet) WeightedRunesBuffer(r1, r2 []rune, maxCost float64, d []float64) float64 {
+	if CompareSlices(r1, r2) {
+		return 0
+	}
+
+	r1Len := len(r1)
+	r2Len := len(r2)
+
+	// d is a matrix of (r1Len+1) rows of (r2Len+1) columns
+	cols := r2Len + 1
+	if size := (r1Len + 1) * cols; len(d) < size {
+		d = make([]float64, size)
+	}
+
+	for j := 0; j <= r2Len; j++ {
+		d[j] = float64(j)
+	}
+
+	// A rule may reach back as many rows as its from side is long, so the
+	// lookup can only stop early once that many rows are over the cost
+	rowMins := make([]float64, r1Len+1)
+
+	for i := 1; i <= r1Len; i++ {
+		d[i*cols] = float64(i)
+		rowMin := d[i*cols]
+
+		for j := 1; j <= r2Len; j++ {
+			cost := 1.0
+			if r1[i-1] == r2[j-1] {
+				cost = 0
+			}
+
+			current := math.Min(d[(i-1)*cols+j-1]+cost, // substitution
+				math.Min(d[(i-1)*cols+j]+1, // deletion
+					d[i*cols+j-1]+1)) // insertion
+
+			if i > 1 && j > 1 && r1[i-1] == r2[j-2] && r1[i-2] == r2[j-1] {
+				current = math.Min(current, d[(i-2)*cols+j-2]+1) // transposition
+			}
+
+			for _, rule := range rs.rules {
+				if rule.matches(r1[:i], r2[:j]) {
+					current = math.Min(current, d[(i-len(rule.from))*cols+j-len(rule.to)]+rule.cost)
+				}
+			}
+
+			d[i*cols+j] = current
+			if current < rowMin {
+				rowMin = current
+			}
+		}
+
+		rowMins[i] = rowMin
+
+		if window := Max(rs.maxFrom, 1); i >= window {
+			exceeded := true
+			for k := i - window + 1; k <= i; k++ {
+				if rowMins[k] <= maxCost {
+					exceeded = false
+					break
+				}
+			}
+			if exceeded {
+				return -1
+			}
+		}
+	}
+
+	if cost := d[r1Len*cols+r2Len]; cost <= maxCost {
+		return cost
+	}
+
+	return -1
+}
+
+// Runes adapts the weighted cost to the contract of the other distance
+// functions, rounding it up to a whole number of edits, so it can be used
+// with DistanceFunc
+func (rs *RuleSet) Runes(r1, r2 []rune, maxDist int) int {
+	cost := rs.WeightedRunes(r1, r2, float64(maxDist))
+	if cost < 0 {
+		return -1
+	}
+	// Allow for rounding errors when rule costs add up to a whole number
+	return int(math.Ceil(cost - 1e-9))
+}
+
+// Variants returns the strings the rules turn input into, with the cost of the
+// rules applied, for up to maxCost. The input itself is included at no cost.
+func (rs *RuleSet) Variants(input string, maxCost float64) map[string]float64 {
+	variants := map[string]float64{input: 0}
+	queue := []string{input}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		runes := []rune(current)
+		cost := variants[current]
+
+		for i := range runes {
+			for _, rule := range rs.rules {
+				if cost+rule.cost > maxCost || !hasPrefixRunes(runes[i:], rule.from) {
+					continue
+				}
+
+				variant := make([]rune, 0, len(runes)-len(rule.from)+len(rule.to))
+				variant = append(variant, runes[:i]...)
+				variant = append(variant, rule.to...)
+				variant = append(variant, runes[i+len(rule.from):]...)
+
+				str := string(variant)
+				if known, exists := variants[str]; !exists || cost+rule.cost < known {
+					variants[str] = cost + rule.cost
+					queue = append(queue, str)
+				}
+			}
+		}
+	}
+
+	return variants
+}
+
+// matches reports whether r1 ends with the from side of the rule and r2 with
+// its to side
+func (rule substitutionRule) matches(r1, r2 []rune) bool {
+	if len(r1) < len(rule.from) || len(r2) < len(rule.to) {
+		return false
+	}
+	return CompareSlices(r1[len(r1)-len(rule.from):], rule.from) &&
+		CompareSlices(r2[len(r2)-len(rule.to):], rule.to)
+}
+
+func hasPrefixRunes(runes, prefix []rune) bool {
+	return len(runes) >= len(prefix) && CompareSlices(runes[:len(prefix)], prefix)
+}
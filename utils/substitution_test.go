@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRuleSet(t *testing.T) {
+	rs := OCRRuleSet()
+
+	tests := []struct {
+		a, b    string
+		maxCost float64
+		want    float64
+	}{
+		{"", "", 10, 0},
+		{"", "testing", 10, 7},
+		{"testing", "", 10, 7},
+		{"testing", "testing", 10, 0},
+		{"rnodern", "modem", 10, 2},
+		{"rnodern", "modem", 1, -1},
+		{"modem", "rnodern", 10, 4},
+		{"clog", "dog", 10, 1},
+		{"liome", "home", 10, 1},
+		{"salt", "slat", 10, 1},
+		{"abcd", "efgh", 3, -1},
+		{"abcd", "efgh", 4, 4},
+		{"Kätzchen", "Katzchen", 10, 1},
+	}
+
+	for i, d := range tests {
+		n := rs.Weighted(d.a, d.b, d.maxCost)
+		if n != d.want {
+			t.Errorf("Test[%d]: Weighted(%q,%q,%v) returned %v, want %v",
+				i, d.a, d.b, d.maxCost, n, d.want)
+		}
+
+		r1 := []rune(d.a)
+		r2 := []rune(d.b)
+
+		n2 := rs.WeightedRunes(r1, r2, d.maxCost)
+		if n != n2 {
+			t.Error("Weighted() is not equal to WeightedRunes()")
+		}
+
+		buf := make([]float64, (len(r1)+1)*(len(r2)+1))
+		n3 := rs.WeightedRunesBuffer(r1, r2, d.maxCost, buf)
+		if n != n3 {
+			t.Error("Weighted() is not equal to WeightedRunesBuffer()")
+		}
+	}
+
+	cheap, err := NewRuleSet(SubstitutionRule{From: "rn", To: "m", Cost: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := cheap.Runes([]rune("rnodern"), []rune("modem"), 2); d != 1 {
+		t.Errorf("Runes() returned %v, want 1", d)
+	}
+}
+
+func TestNewRuleSet(t *testing.T) {
+	invalid := []SubstitutionRule{
+		{From: "", To: "m", Cost: 1},
+		{From: "rn", To: "", Cost: 1},
+		{From: "rn", To: "rn", Cost: 1},
+		{From: "rn", To: "m", Cost: 0},
+	}
+	for i, rule := range invalid {
+		if _, err := NewRuleSet(rule); err == nil {
+			t.Errorf("Test[%d]: expected an error for %+v", i, rule)
+		}
+	}
+}
+
+func TestReadRuleSet(t *testing.T) {
+	rs, err := ReadRuleSet(strings.NewReader("# OCR\nrn m\n\ncl d 0.5\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules := rs.Rules()
+	if len(rules) != 2 || rules[0] != (SubstitutionRule{From: "rn", To: "m", Cost: 1}) ||
+		rules[1] != (SubstitutionRule{From: "cl", To: "d", Cost: 0.5}) {
+		t.Fatalf("Unexpected rules %+v", rules)
+	}
+
+	for _, text := range []string{"rn", "rn m 1 2", "rn m x", "rn rn"} {
+		if _, err := ReadRuleSet(strings.NewReader(text)); err == nil {
+			t.Errorf("Expected an error for %q", text)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "rules.txt")
+	if err := os.WriteFile(path, []byte("vv w\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if rs, err = LoadRuleSet(path); err != nil {
+		t.Fatal(err)
+	}
+	if d := rs.Runes([]rune("vvord"), []rune("word"), 2); d != 1 {
+		t.Errorf("Runes() returned %v, want 1", d)
+	}
+}
+
+func TestRuleSet_Variants(t *testing.T) {
+	rs := OCRRuleSet()
+
+	variants := rs.Variants("rnodern", 2)
+	want := map[string]float64{
+		"rnodern": 0,
+		"modern":  1,
+		"rnodem":  1,
+		"modem":   2,
+	}
+	if len(variants) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, variants)
+	}
+	for variant, cost := range want {
+		if variants[variant] != cost {
+			t.Errorf("Expected %q to cost %v, got %v", variant, cost, variants[variant])
+		}
+	}
+
+	if variants = rs.Variants("rnodern", 0); len(variants) != 1 {
+		t.Errorf("Expected only the input, got %v", variants)
+	}
+}
+
+func BenchmarkRuleSet(b *testing.B) {
+	rs := OCRRuleSet()
+
+	tests := []struct {
+		a, b    string
+		maxCost float64
+		name    string
+	}{
+		{"levenshtein", "frankenstein", 10, "ASCII"},
+		{"Kätzchen", "Katzchen", 10, "UTF8"},
+	}
+	for _, test := range tests {
+		b.Run(test.name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				rs.Weighted(test.a, test.b, test.maxCost)
+			}
+		})
+		b.Run(test.name+"Runes", func(b *testing.B) {
+			r1 := []rune(test.a)
+			r2 := []rune(test.b)
+			for n := 0; n < b.N; n++ {
+				rs.WeightedRunes(r1, r2, test.maxCost)
+			}
+		})
+		b.Run(test.name+"RunesBuffer", func(b *testing.B) {
+			r1 := []rune(test.a)
+			r2 := []rune(test.b)
+			buf := make([]float64, (len(r1)+1)*(len(r2)+1))
+			for n := 0; n < b.N; n++ {
+				rs.WeightedRunesBuffer(r1, r2, test.maxCost, buf)
+			}
+		})
+	}
+}
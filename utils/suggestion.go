@@ -6,6 +6,21 @@ import "strings"
 type Suggestion struct {
 	// The distance between this suggestion and the input word
 	Distance int
+	// The weighted cost of the edits between this suggestion and the input
+	// word. It equals Distance unless a weighted distance function is used
+	Cost float64
+	// Whether the suggestion was found through the phonetic index rather
+	// than the edit distance
+	Phonetic bool
+	// The probability of this suggestion being meant by the input word. It
+	// is only set when suggestions are ranked by context or by the noisy
+	// channel model
+	Probability float64
+	// The name of the dictionary the suggestion was found in
+	Dictionary string
+	// The edits that turn the input word into this suggestion. They are
+	// only set when requested with the EditScripts lookup option
+	Edits []Edit
 	Entry
 }
 
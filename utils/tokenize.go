@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token is a word found in a text along with its position. Start and End are
+// byte offsets, RuneStart and RuneEnd are rune offsets, both half open.
+type Token struct {
+	Text      string
+	Start     int
+	End       int
+	RuneStart int
+	RuneEnd   int
+}
+
+// Tokenize splits a text into words, skipping whitespace and punctuation.
+// Apostrophes between two word characters are kept so contractions such as
+// "don't" stay a single token.
+func Tokenize(text string) []Token {
+	tokens := []Token{}
+
+	start, runeStart := -1, 0
+	runeIdx := 0
+
+	for i, r := range text {
+		if isWordRune(r) || (start >= 0 && isApostrophe(r) && nextIsWordRune(text, i)) {
+			if start < 0 {
+				start, runeStart = i, runeIdx
+			}
+		} else if start >= 0 {
+			tokens = append(tokens, Token{
+				Text:      text[start:i],
+				Start:     start,
+				End:       i,
+				RuneStart: runeStart,
+				RuneEnd:   runeIdx,
+			})
+			start = -1
+		}
+		runeIdx++
+	}
+
+	if start >= 0 {
+		tokens = append(tokens, Token{
+			Text:      text[start:],
+			Start:     start,
+			End:       len(text),
+			RuneStart: runeStart,
+			RuneEnd:   runeIdx,
+		})
+	}
+
+	return tokens
+}
+
+// HasLetter reports whether str contains at least one letter
+func HasLetter(str string) bool {
+	for _, r := range str {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
+
+func isApostrophe(r rune) bool {
+	return r == '\'' || r == '’'
+}
+
+func nextIsWordRune(text string, i int) bool {
+	_, size := utf8.DecodeRuneInString(text[i:])
+	next, _ := utf8.DecodeRuneInString(text[i+size:])
+	return i+size < len(text) && isWordRune(next)
+}
@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"sync"
+)
+
+type trieNode struct {
+	children map[rune]*trieNode
+	word     bool
+}
+
+// PrefixIndex is a trie of the words of each dictionary, used to find the
+// words that start with a prefix
+type PrefixIndex struct {
+	sync.RWMutex
+	roots map[string]*trieNode
+}
+
+// NewPrefixIndex creates a new, empty prefix index
+func NewPrefixIndex() *PrefixIndex {
+	return &PrefixIndex{
+		roots: make(map[string]*trieNode),
+	}
+}
+
+// Add a word to a given dictionary
+func (pi *PrefixIndex) Add(dict, word string) {
+	pi.Lock()
+	defer pi.Unlock()
+
+	node, exists := pi.roots[dict]
+	if !exists {
+		node = &trieNode{}
+		pi.roots[dict] = node
+	}
+
+	for _, r := range word {
+		child, exists := node.children[r]
+		if !exists {
+			if node.children == nil {
+				node.children = make(map[rune]*trieNode)
+			}
+			child = &trieNode{}
+			node.children[r] = child
+		}
+		node = child
+	}
+
+	node.word = true
+}
+
+// Remove a word from a given dictionary, pruning the nodes no other word
+// goes through
+func (pi *PrefixIndex) Remove(dict, word string) {
+	pi.Lock()
+	defer pi.Unlock()
+
+	root, exists := pi.roots[dict]
+	if !exists {
+		return
+	}
+
+	runes := []rune(word)
+	nodes := make([]*trieNode, 0, len(runes)+1)
+	nodes = append(nodes, root)
+
+	for _, r := range runes {
+		child, exists := nodes[len(nodes)-1].children[r]
+		if !exists {
+			return
+		}
+		nodes = append(nodes, child)
+	}
+
+	nodes[len(nodes)-1].word = false
+
+	for i := len(runes); i > 0; i-- {
+		if node := nodes[i]; node.word || len(node.children) > 0 {
+			break
+		}
+		delete(nodes[i-1].children, runes[i-1])
+	}
+}
+
+// Match returns the words of a given dictionary that start with a prefix
+// within maxDist edits of prefix, mapped to the number of edits. Edits are
+// insertions, deletions, substitutions and transpositions of adjacent
+// characters.
+func (pi *PrefixIndex) Match(dict, prefix string, maxDist int) map[string]int {
+	pi.RLock()
+	defer pi.RUnlock()
+
+	matches := make(map[string]int)
+
+	root, exists := pi.roots[dict]
+	if !exists {
+		return matches
+	}
+
+	p := []rune(prefix)
+	pLen := len(p)
+
+	// row[i] is the distance between the first i runes of the prefix and the
+	// path to the current node
+	row := make([]int, pLen+1)
+	for i := range row {
+		row[i] = i
+	}
+
+	best := -1
+	if pLen <= maxDist {
+		best = pLen
+	}
+
+	var walk func(node *trieNode, path []rune, prev, row []int, best int)
+	walk = func(node *trieNode, path []rune, prev, row []int, best int) {
+		if node.word && best >= 0 {
+			matches[string(path)] = best
+		}
+
+		for r, child := range node.children {
+			next := make([]int, pLen+1)
+			next[0] = row[0] + 1
+			rowMin := next[0]
+
+			for i := 1; i <= pLen; i++ {
+				cost := 1
+				if p[i-1] == r {
+					cost = 0
+				}
+
+				next[i] = Min(row[i]+1, next[i-1]+1, row[i-1]+cost)
+				if prev != nil && i > 1 && p[i-1] == path[len(path)-1] && p[i-2] == r {
+					next[i] = Min(next[i], prev[i-2]+1)
+				}
+
+				if next[i] < rowMin {
+					rowMin = next[i]
+				}
+			}
+
+			// Once the path is close enough to the prefix, every word below
+			// it is a match
+			childBest := best
+			if next[pLen] <= maxDist && (childBest < 0 || next[pLen] < childBest) {
+				childBest = next[pLen]
+			}
+
+			if rowMin > maxDist && childBest < 0 {
+				continue
+			}
+
+			walk(child, append(path, r), row, next, childBest)
+		}
+	}
+
+	walk(root, nil, nil, row, best)
+
+	return matches
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPrefixIndex(t *testing.T) {
+	pi := NewPrefixIndex()
+	for _, word := range []string{"apple", "application", "apply", "banana", "ape"} {
+		pi.Add("default", word)
+	}
+	pi.Add("other", "apricot")
+
+	tests := []struct {
+		prefix  string
+		maxDist int
+		want    map[string]int
+	}{
+		{"app", 0, map[string]int{"apple": 0, "application": 0, "apply": 0}},
+		{"apl", 0, map[string]int{}},
+		{"apl", 1, map[string]int{"apple": 1, "application": 1, "apply": 1, "ape": 1}},
+		{"pap", 1, map[string]int{"apple": 1, "application": 1, "apply": 1, "ape": 1}},
+		{"", 0, map[string]int{"apple": 0, "application": 0, "apply": 0, "banana": 0, "ape": 0}},
+		{"xyz", 2, map[string]int{}},
+	}
+
+	for i, d := range tests {
+		if got := pi.Match("default", d.prefix, d.maxDist); !reflect.DeepEqual(got, d.want) {
+			t.Errorf("Test[%d]: Match(%q, %d) returned %v, want %v",
+				i, d.prefix, d.maxDist, got, d.want)
+		}
+	}
+
+	pi.Remove("default", "apple")
+	pi.Remove("default", "missing")
+	want := map[string]int{"application": 0, "apply": 0}
+	if got := pi.Match("default", "app", 0); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected %v after removal, got %v", want, got)
+	}
+
+	pi.Remove("default", "application")
+	pi.Remove("default", "apply")
+	if len(pi.roots["default"].children['a'].children['p'].children) != 1 {
+		t.Fatal("Expected nodes of removed words to be pruned")
+	}
+}
@@ -0,0 +1,95 @@
+package utils
+
+// UnrestrictedDamerauLevenshtein takes two strings and a maximum edit distance
+// and returns the number of edits to transform one string to another, or -1 if
+// the distance is greater than the maximum distance. Unlike DamerauLevenshtein,
+// which is the optimal string alignment distance, characters may be edited
+// again after being transposed, so "ca" to "abc" takes 2 edits rather than 3.
+func UnrestrictedDamerauLevenshtein(str1, str2 string, maxDist int) int {
+	return UnrestrictedDamerauLevenshteinRunes([]rune(str1), []rune(str2), maxDist)
+}
+
+// UnrestrictedDamerauLevenshteinRunes is the same as
+// UnrestrictedDamerauLevenshtein but accepts runes instead of strings
+func UnrestrictedDamerauLevenshteinRunes(r1, r2 []rune, maxDist int) int {
+	return UnrestrictedDamerauLevenshteinRunesBuffer(r1, r2, maxDist, nil)
+}
+
+// UnrestrictedDamerauLevenshteinRunesBuffer is the same as
+// UnrestrictedDamerauLevenshteinRunes but also accepts a memory buffer d which
+// should be of size (len(r1)+2)*(len(r2)+2)
+func UnrestrictedDamerauLevenshteinRunesBuffer(r1, r2 []rune, maxDist int, d []int) int {
+	if CompareSlices(r1, r2) {
+		return 0
+	}
+
+	r1Len := len(r1)
+	r2Len := len(r2)
+
+	if Abs(r1Len-r2Len) > maxDist {
+		return -1
+	}
+
+	// d is a matrix of (r1Len+2) rows of (r2Len+2) columns, where the first
+	// row and column hold a distance larger than any other
+	cols := r2Len + 2
+	if size := (r1Len + 2) * cols; len(d) < size {
+		d = make([]int, size)
+	}
+
+	inf := r1Len + r2Len
+	d[0] = inf
+	for i := 0; i <= r1Len; i++ {
+		d[(i+1)*cols] = inf
+		d[(i+1)*cols+1] = i
+	}
+	for j := 0; j <= r2Len; j++ {
+		d[j+1] = inf
+		d[cols+j+1] = j
+	}
+
+	// The last row each rune of r1 was seen in
+	lastRow := make(map[rune]int)
+
+	for i := 1; i <= r1Len; i++ {
+		// The last column of the current row where the runes matched
+		lastMatchCol := 0
+		rowMin := inf
+
+		for j := 1; j <= r2Len; j++ {
+			k := lastRow[r2[j-1]]
+			l := lastMatchCol
+
+			cost := 1
+			if r1[i-1] == r2[j-1] {
+				cost = 0
+				lastMatchCol = j
+			}
+
+			current := Min(
+				d[i*cols+j]+cost,              // substitution
+				d[(i+1)*cols+j]+1,             // insertion
+				d[i*cols+j+1]+1,               // deletion
+				d[k*cols+l]+(i-k-1)+1+(j-l-1), // transposition
+			)
+			d[(i+1)*cols+j+1] = current
+
+			if current < rowMin {
+				rowMin = current
+			}
+		}
+
+		// The minimum of a row never decreases in the next rows
+		if rowMin > maxDist {
+			return -1
+		}
+
+		lastRow[r1[i-1]] = i
+	}
+
+	if dist := d[(r1Len+1)*cols+r2Len+1]; dist <= maxDist {
+		return dist
+	}
+
+	return -1
+}
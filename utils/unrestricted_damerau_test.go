@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestUnrestrictedDamerauLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		maxDist int
+		want    int
+	}{
+		{"", "", 10, 0},
+		{"", "testing", 10, 7},
+		{"testing", "", 10, 7},
+		{"testing", "testing", 10, 0},
+		{"ab", "aa", 10, 1},
+		{"ab", "ba", 10, 1},
+		{"ab", "aaa", 10, 2},
+		{"bbb", "a", 10, 3},
+		{"abcd", "efgh", 3, -1},
+		{"abcd", "efgh", 4, 4},
+		{"ca", "abc", 10, 2},
+		{"ca", "abc", 1, -1},
+		{"abc", "ca", 10, 2},
+		{"salt", "slat", 10, 1},
+		{"saturday", "sunday", 10, 3},
+		{"distance", "difference", 10, 5},
+		{"levenshtein", "frankenstein", 10, 6},
+		{"the cat and dog", "the cats and dogs", 10, 2},
+	}
+	for i, d := range tests {
+		n := UnrestrictedDamerauLevenshtein(d.a, d.b, d.maxDist)
+		if n != d.want {
+			t.Errorf("Test[%d]: UnrestrictedDamerauLevenshtein(%q,%q,%v) returned %v, want %v",
+				i, d.a, d.b, d.maxDist, n, d.want)
+		}
+
+		r1 := []rune(d.a)
+		r2 := []rune(d.b)
+
+		n2 := UnrestrictedDamerauLevenshteinRunes(r1, r2, d.maxDist)
+		if n != n2 {
+			t.Error("UnrestrictedDamerauLevenshtein() is not equal to UnrestrictedDamerauLevenshteinRunes()")
+		}
+
+		buf := make([]int, (len(r1)+2)*(len(r2)+2))
+		n3 := UnrestrictedDamerauLevenshteinRunesBuffer(r1, r2, d.maxDist, buf)
+		if n != n3 {
+			t.Error("UnrestrictedDamerauLevenshtein() is not equal to UnrestrictedDamerauLevenshteinRunesBuffer()")
+		}
+	}
+}
+
+func BenchmarkUnrestrictedDamerauLevenshtein(b *testing.B) {
+	tests := []struct {
+		a, b    string
+		maxDist int
+		name    string
+	}{
+		{"levenshtein", "frankenstein", 10, "ASCII"},
+		{"Kätzchen", "Katzchen", 10, "UTF8"},
+	}
+	for _, test := range tests {
+		b.Run(test.name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				UnrestrictedDamerauLevenshtein(test.a, test.b, test.maxDist)
+			}
+		})
+		b.Run(test.name+"Runes", func(b *testing.B) {
+			r1 := []rune(test.a)
+			r2 := []rune(test.b)
+			for n := 0; n < b.N; n++ {
+				UnrestrictedDamerauLevenshteinRunes(r1, r2, test.maxDist)
+			}
+		})
+		b.Run(test.name+"RunesBuffer", func(b *testing.B) {
+			r1 := []rune(test.a)
+			r2 := []rune(test.b)
+			buf := make([]int, (len(r1)+2)*(len(r2)+2))
+			for n := 0; n < b.N; n++ {
+				UnrestrictedDamerauLevenshteinRunesBuffer(r1, r2, test.maxDist, buf)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ParseValue converts a value of WordData read as text into a number or a
+// boolean when it is one, so that it compares equal to the same value decoded
+// from JSON. Numbers are float64, like encoding/json decodes them. Text in
+// double quotes is a JSON string and is kept as text, e.g. "\"007\"" is the
+// string "007" rather than the number 7.
+func ParseValue(text string) interface{} {
+	if strings.HasPrefix(text, `"`) {
+		var str string
+		if err := json.Unmarshal([]byte(text), &str); err == nil {
+			return str
+		}
+	}
+
+	switch text {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	// Words such as "nan" and "infinity" are kept as text
+	if f, err := strconv.ParseFloat(text, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+		return f
+	}
+	return text
+}
+
+// FormatValue converts a value of WordData into text that ParseValue reads
+// back, with values other than strings, numbers and booleans written as JSON.
+// Strings that ParseValue would read as something else, such as "007" or
+// "true", are written as JSON strings.
+func FormatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		if parsed, ok := ParseValue(v).(string); ok && parsed == v {
+			return v
+		}
+		text, _ := json.Marshal(v)
+		return string(text)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+
+	if f, ok := toFloat(value); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	text, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(text)
+}
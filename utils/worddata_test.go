@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseValue(t *testing.T) {
+	tests := []struct {
+		text string
+		want interface{}
+	}{
+		{"", ""},
+		{"noun", "noun"},
+		{"true", true},
+		{"false", false},
+		{"True", "True"},
+		{"42", float64(42)},
+		{"-0.5", -0.5},
+		{"1e3", float64(1000)},
+		{"NaN", "NaN"},
+		{"inf", "inf"},
+		{`"007"`, "007"},
+		{`"true"`, "true"},
+		{`"a "quote"`, `"a "quote"`},
+	}
+	for i, d := range tests {
+		v := ParseValue(d.text)
+		if !reflect.DeepEqual(v, d.want) {
+			t.Errorf("Test[%d]: ParseValue(%q) returned %#v, want %#v", i, d.text, v, d.want)
+		}
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{"noun", "noun"},
+		{"007", `"007"`},
+		{"1e3", `"1e3"`},
+		{"false", `"false"`},
+		{`"quoted"`, `"\"quoted\""`},
+		{true, "true"},
+		{float64(42), "42"},
+		{0.25, "0.25"},
+		{7, "7"},
+		{uint64(3), "3"},
+		{[]interface{}{"a", 1.0}, `["a",1]`},
+	}
+	for i, d := range tests {
+		s := FormatValue(d.value)
+		if s != d.want {
+			t.Errorf("Test[%d]: FormatValue(%#v) returned %q, want %q", i, d.value, s, d.want)
+		}
+		if str, ok := d.value.(string); ok && ParseValue(s) != str {
+			t.Errorf("Test[%d]: ParseValue(%q) returned %#v, want %q", i, s, ParseValue(s), str)
+		}
+	}
+}